@@ -0,0 +1,314 @@
+package rady
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+)
+
+/*
+Interceptor can be implemented by any singleton bean, to wrap methods of beans marked Service or Repository
+
+methods of a bean are intercepted when
+
+	1. name of the interceptor is in `intercept` tag of the bean, all methods of the bean are intercepted
+
+	2. a pattern in `pointcut` tag of the interceptor matches name of the method, or `TypeName.Method` when pattern contains '.'
+
+patterns are matched by path.Match, interceptors are called in order of `order` tag, see SortByOrder
+
+an error returned by Intercept replaces the last result of the method when it is error, or panics when it isn't
+
+Usage:
+
+	type RetryInterceptor struct {
+		Component `pointcut:"Get*,UserService.Save*" order:"1"`
+	}
+
+	func (r *RetryInterceptor) Intercept(invocation *Invocation) (err error) {
+		for i := 0; i < 3; i++ {
+			if err = invocation.Proceed(); err == nil {
+				return
+			}
+		}
+		return
+	}
+
+	type UserService struct {
+		Service `intercept:"GetTimingInterceptor"`
+	}
+
+only Interface injection points receive the wrapped bean, by a proxy registered by RegisterProxy
+*/
+type Interceptor interface {
+	Intercept(invocation *Invocation) error
+}
+
+// InterceptorType is the type of Interceptor
+var InterceptorType = reflect.TypeOf((*Interceptor)(nil)).Elem()
+
+// IsInterceptor return true when the bean implements Interceptor
+func IsInterceptor(bean *Bean) bool {
+	return bean.Type.Implements(InterceptorType)
+}
+
+/*
+Invocation is a call of method intercepted
+
+Name and Type is the bean, Method is name of the method, Args is params passed to the method,
+and Results is results of the method, zero values before Proceed is called, an interceptor can set them without calling Proceed
+*/
+type Invocation struct {
+	Name    string
+	Type    reflect.Type
+	Method  string
+	Args    []reflect.Value
+	Results []reflect.Value
+	target  reflect.Value
+	chain   []Interceptor
+	index   int
+}
+
+/*
+Proceed call the next interceptor, or the method when there is none, and set Results
+
+Proceed can be called more than once, it returns the last result of the method when it is error
+*/
+func (i *Invocation) Proceed() error {
+	if i.index < len(i.chain) {
+		next := *i
+		next.index++
+		next.Results = ZeroResults(i.target.Type())
+		err := i.chain[i.index].Intercept(&next)
+		i.Results = next.Results
+		if err != nil {
+			i.setError(err)
+		}
+	} else {
+		i.Results = i.target.Call(i.Args)
+	}
+	return ResultError(i.Results)
+}
+
+// setError set err as the last result, panic when the last result is not error
+func (i *Invocation) setError(err error) {
+	last := len(i.Results) - 1
+	if last < 0 || i.Results[last].Type() != ErrorType {
+		panic(fmt.Sprintf("%s of [%s][%s] failed: %s", i.Method, i.Type, i.Name, err))
+	}
+	i.Results[last] = reflect.ValueOf(&err).Elem()
+}
+
+// ZeroResults return zero values of results of methodType
+func ZeroResults(methodType reflect.Type) []reflect.Value {
+	results := make([]reflect.Value, methodType.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(methodType.Out(i))
+	}
+	return results
+}
+
+// ResultError return the last one of results when it is non-nil error
+func ResultError(results []reflect.Value) error {
+	if last := len(results) - 1; last >= 0 && results[last].Type() == ErrorType && !results[last].IsNil() {
+		return results[last].Interface().(error)
+	}
+	return nil
+}
+
+/*
+Proxy wraps methods of a bean with interceptors, it is embedded in proxies registered by RegisterProxy
+
+Target is the bean, or the proxy of bean returned by BeanPostProcessor
+*/
+type Proxy struct {
+	Target reflect.Value
+	Bean   *Bean
+	chains map[string][]Interceptor
+}
+
+/*
+Call call method of Target by name with args, through interceptors of the method
+
+nil in args is converted to zero value of the param
+
+Usage:
+
+	func (p *UserServiceProxy) GetUser(id int) (*User, error) {
+		results := p.Call("GetUser", id)
+		err, _ := results[1].Interface().(error)
+		return results[0].Interface().(*User), err
+	}
+*/
+func (p *Proxy) Call(method string, args ...interface{}) []reflect.Value {
+	methodValue := p.Target.MethodByName(method)
+	if !methodValue.IsValid() {
+		panic(fmt.Sprintf("%s has no method %s", p.Target.Type(), method))
+	}
+	methodType := methodValue.Type()
+	argValues := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			argValues[i] = reflect.Zero(GetParamType(methodType, i))
+		} else {
+			argValues[i] = reflect.ValueOf(arg)
+		}
+	}
+	invocation := &Invocation{
+		Name:    p.Bean.Name,
+		Type:    p.Bean.Type,
+		Method:  method,
+		Args:    argValues,
+		Results: ZeroResults(methodType),
+		target:  methodValue,
+		chain:   p.chains[method],
+	}
+	invocation.Proceed()
+	return invocation.Results
+}
+
+// GetParamType return type of the ith param of methodType, element type of the variadic param when i is out of fixed params
+func GetParamType(methodType reflect.Type, i int) reflect.Type {
+	if methodType.IsVariadic() && i >= methodType.NumIn()-1 {
+		return methodType.In(methodType.NumIn() - 1).Elem()
+	}
+	return methodType.In(i)
+}
+
+/*
+RegisterProxy register factory of proxy for an Interface, factory should be `func(*Proxy) I` and I is Interface
+
+intercepted beans implement I are injected into Interface injection points as proxies returned by factory
+
+Usage:
+
+	type UserServiceProxy struct {
+		*Proxy
+	}
+
+	app.RegisterProxy(func(proxy *Proxy) UserRepository {
+		return &UserServiceProxy{proxy}
+	})
+*/
+func (a *Application) RegisterProxy(factory interface{}) *Application {
+	factoryType := reflect.TypeOf(factory)
+	if factoryType == nil || factoryType.Kind() != reflect.Func ||
+		factoryType.NumIn() != 1 || factoryType.In(0) != reflect.TypeOf(new(Proxy)) ||
+		factoryType.NumOut() != 1 || factoryType.Out(0).Kind() != reflect.Interface {
+		a.addError(NewBeanError(InvalidBean, factoryType, "", "", "proxy factory should be `func(*Proxy) I` and I is Interface"))
+		return a
+	}
+	a.ProxyMap[factoryType.Out(0)] = reflect.ValueOf(factory)
+	return a
+}
+
+// IsInterceptable return true when the bean is marked Service or Repository
+func IsInterceptable(bean *Bean) bool {
+	if !CheckPtrOfStruct(bean.Type) {
+		return false
+	}
+	for i := 0; i < bean.Type.Elem().NumField(); i++ {
+		if fieldType := bean.Type.Elem().Field(i).Type; fieldType == reflect.TypeOf(Service{}) || fieldType == reflect.TypeOf(Repository{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTagList return values split by ',' of key in tag of the bean or its component tag, spaces are trimmed
+func GetTagList(bean *Bean, key string) []string {
+	value := bean.Tag.Get(key)
+	if value == "" {
+		value = GetComponentTag(bean.Type, key)
+	}
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.Trim(item, " "); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// MatchPointcut return true when one of patterns matches method, or `TypeName.Method` of the bean when pattern contains '.'
+func MatchPointcut(patterns []string, bean *Bean, method string) bool {
+	for _, pattern := range patterns {
+		name := method
+		if strings.Contains(pattern, ".") {
+			name = bean.Type.Elem().Name() + "." + method
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// interceptorChain return interceptors of the method, interceptors are sorted by SortByOrder
+func interceptorChain(bean *Bean, interceptors []*Bean, method string) []Interceptor {
+	names := make(map[string]bool)
+	for _, name := range GetTagList(bean, "intercept") {
+		names[name] = true
+	}
+	chain := make([]Interceptor, 0)
+	for _, interceptor := range interceptors {
+		if names[interceptor.Name] || MatchPointcut(GetTagList(interceptor, "pointcut"), bean, method) {
+			chain = append(chain, interceptor.Value.Addr().Interface().(Interceptor))
+		}
+	}
+	return chain
+}
+
+/*
+intercept inject proxies of the bean into Interface injection points of mothers, when some of its methods are intercepted
+
+a proxy is created for every Interface in ProxyMap the bean implements, and Warning is logged for Interface injection points without proxy
+*/
+func (a *Application) intercept(bean *Bean, interceptors, mothers []*Bean) {
+	if len(interceptors) == 0 || !IsInterceptable(bean) || IsInterceptor(bean) {
+		return
+	}
+
+	proxied := make(map[reflect.Type]bool)
+	for interfaceType, factory := range a.ProxyMap {
+		target := bean.Expose(interfaceType)
+		if !target.Type().Implements(interfaceType) {
+			continue
+		}
+		chains := make(map[string][]Interceptor)
+		for i := 0; i < interfaceType.NumMethod(); i++ {
+			method := interfaceType.Method(i).Name
+			if chain := interceptorChain(bean, interceptors, method); len(chain) > 0 {
+				chains[method] = chain
+			}
+		}
+		if len(chains) == 0 {
+			continue
+		}
+		proxy := factory.Call([]reflect.Value{reflect.ValueOf(&Proxy{Target: target, Bean: bean, chains: chains})})[0].Elem()
+		if !proxy.IsValid() {
+			a.Logger.Warning("Proxy of %s for [%s][%s] is nil", interfaceType, bean.Type, bean.Name)
+			continue
+		}
+		a.Logger.Debug("Intercept [%s][%s] by %s", bean.Type, bean.Name, proxy.Type())
+		a.injectProxy(bean, proxy, mothers)
+		proxied[interfaceType] = true
+	}
+
+	for _, mother := range mothers {
+		for _, dep := range mother.Deps {
+			if dep.Bean != bean || !dep.Field.IsValid() {
+				continue
+			}
+			if fieldType := dep.FieldType(); fieldType.Kind() == reflect.Interface && !proxied[fieldType] {
+				for method := 0; method < fieldType.NumMethod(); method++ {
+					if len(interceptorChain(bean, interceptors, fieldType.Method(method).Name)) > 0 {
+						a.Logger.Warning("%s of [%s][%s] is not intercepted: no proxy of %s is registered", dep.Path, mother.Type, mother.Name, fieldType)
+						break
+					}
+				}
+			}
+		}
+	}
+}
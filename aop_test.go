@@ -0,0 +1,132 @@
+package rady
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+var InterceptedMethods = make([]string, 0)
+
+type (
+	AopRoot struct {
+		*AopConfig
+	}
+
+	AopConfig struct {
+		Configuration
+	}
+
+	TimingInterceptor struct {
+		Component `order:"1"`
+	}
+
+	RetryInterceptor struct {
+		Component `pointcut:"Get*" order:"2"`
+	}
+
+	AopStore interface {
+		GetName(id int) (string, error)
+		Count() int
+	}
+
+	AopRepository struct {
+		Repository `intercept:"GetTimingInterceptor"`
+		Calls      int
+	}
+
+	AopComponent struct {
+		Component
+	}
+
+	AopStoreProxy struct {
+		*Proxy
+	}
+
+	AopTest struct {
+		Testing
+		Store     AopStore `type:"repository"`
+		Direct    *AopRepository
+		Component *AopComponent
+	}
+)
+
+func (c *AopConfig) GetTimingInterceptor() *TimingInterceptor {
+	return new(TimingInterceptor)
+}
+
+func (c *AopConfig) GetRetryInterceptor() *RetryInterceptor {
+	return new(RetryInterceptor)
+}
+
+func (t *TimingInterceptor) Intercept(invocation *Invocation) error {
+	InterceptedMethods = append(InterceptedMethods, invocation.Method)
+	return invocation.Proceed()
+}
+
+func (r *RetryInterceptor) Intercept(invocation *Invocation) (err error) {
+	for i := 0; i < 3; i++ {
+		if err = invocation.Proceed(); err == nil {
+			return
+		}
+	}
+	return
+}
+
+func (r *AopRepository) GetName(id int) (string, error) {
+	r.Calls++
+	if r.Calls < 3 {
+		return "", errors.New("connection refused")
+	}
+	return "rady", nil
+}
+
+func (r *AopRepository) Count() int {
+	return r.Calls
+}
+
+func (c *AopComponent) GetName(id int) (string, error) {
+	return "", errors.New("not intercepted")
+}
+
+func (p *AopStoreProxy) GetName(id int) (string, error) {
+	results := p.Call("GetName", id)
+	err, _ := results[1].Interface().(error)
+	return results[0].String(), err
+}
+
+func (p *AopStoreProxy) Count() int {
+	return int(p.Call("Count")[0].Int())
+}
+
+func (a *AopTest) TestIntercept(t *testing.T) {
+	_, ok := a.Store.(*AopStoreProxy)
+	assert.True(t, ok)
+	name, err := a.Store.GetName(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "rady", name)
+	assert.Equal(t, 3, a.Store.Count())
+	assert.Equal(t, 3, a.Direct.Calls)
+	assert.Equal(t, []string{"GetName", "Count"}, InterceptedMethods)
+
+	_, err = a.Component.GetName(1)
+	assert.Equal(t, "not intercepted", err.Error())
+}
+
+func TestInterceptor(t *testing.T) {
+	InterceptedMethods = make([]string, 0)
+	CreateTest(new(AopRoot)).
+		RegisterProxy(func(proxy *Proxy) AopStore {
+			return &AopStoreProxy{proxy}
+		}).
+		AddTest(new(AopTest)).
+		Test(t)
+}
+
+func TestRegisterProxyError(t *testing.T) {
+	_, err := CreateApplication(new(AopRoot)).RegisterProxy(func(store AopStore) *Proxy { return nil }).Build()
+	invalid := err.(BootErrors).OfKind(InvalidBean)
+	assert.Len(t, invalid, 1)
+	assert.Equal(t, reflect.TypeOf(func(AopStore) *Proxy { return nil }), invalid[0].Type)
+}
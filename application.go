@@ -1,14 +1,22 @@
 package rady
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/labstack/echo"
 	"github.com/tidwall/gjson"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"os/signal"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 )
 
 /*
@@ -37,6 +45,40 @@ Server is the echo server
 Logger is the global logger
 
 ConfigFile is the string json value of config file
+
+ShutdownTimeout is the max duration to drain server and destroy beans, parsed by time.ParseDuration
+
+bootErrors is problems found in bootstrap, returned by Build or Start
+
+lazyLock guards BeanMap, ValueBeanMap and bootErrors after bootstrap, because providers may load beans lazily at any time
+
+reloadLock serializes ReloadValues, requests are never blocked by it
+
+shutdownOnce makes Shutdown run only once, shutdownErr is its result returned by every call
+
+prepared is true after Prepare, params of factories registered later are loaded when they are registered
+
+created is instances of prototype and request beans, collected when they are instantiated
+
+onMissing is loaders of conditional beans tagged `on-missing`, see loadConditionally
+
+ProxyMap is map to find factory of proxy by Interface, see RegisterProxy
+
+StartupReport is true when a table of beans should be logged after boot, see Describe
+
+Defaults is default values of keys provided by modules, see ModuleDefaults
+
+Publisher is the event bus, see EventPublisher
+
+Scheduler runs methods tagged by Scheduled, see Scheduled
+
+Watcher reloads config file when it is changed, see ConfigWatcher
+
+Overrides is values set by command-line flags, it is nil until Args is called, see Args
+
+namespace is the namespace of the module being loaded, see Module
+
+workers is Runnable beans started with server, see Runnable
 */
 type Application struct {
 	BootStrap
@@ -53,6 +95,24 @@ type Application struct {
 	Logger          *Logger
 	ConfigFile      string
 	Addr            *string `value:"rady.server.addr" default:":8081"`
+	ShutdownTimeout *string `value:"rady.server.shutdown-timeout" default:"10s"`
+	ProxyMap        map[reflect.Type]reflect.Value
+	StartupReport   *bool `value:"rady.startup-report" default:"false"`
+	Defaults        map[string]gjson.Result
+	Publisher       *EventPublisher
+	Scheduler       *Scheduler
+	Watcher         *ConfigWatcher
+	Overrides       map[string]gjson.Result
+	bootErrors      BootErrors
+	lazyLock        sync.Mutex
+	reloadLock      sync.Mutex
+	shutdownOnce    sync.Once
+	shutdownErr     error
+	prepared        bool
+	created         []*Bean
+	onMissing       []func()
+	namespace       string
+	workers         *Workers
 }
 
 /*
@@ -62,6 +122,7 @@ if root is not kinds of Ptr, there will be an error
 */
 func CreateApplication(root interface{}) *Application {
 	if CheckPtrOfStruct(reflect.TypeOf(root)) {
+		logger := NewLogger()
 		return (&Application{
 			Root:            root,
 			BeanMap:         make(map[reflect.Type]map[string]*Bean),
@@ -72,8 +133,14 @@ func CreateApplication(root interface{}) *Application {
 			MdWareBeanMap:   make(map[string]*MdWareBean),
 			Entities:        make([]reflect.Type, 0),
 			TestingBeans:    make([]*TestingBean, 0),
+			ProxyMap:        make(map[reflect.Type]reflect.Value),
+			Defaults:        make(map[string]gjson.Result),
+			bootErrors:      make(BootErrors, 0),
 			Server:          echo.New(),
-			Logger:          NewLogger(),
+			Logger:          logger,
+			Publisher:       NewEventPublisher(logger),
+			Scheduler:       NewScheduler(logger),
+			Watcher:         new(ConfigWatcher),
 		}).init()
 	}
 	NewLogger().Errorf("%s is not kind of Ptr!!!\n", reflect.TypeOf(root).Name())
@@ -85,7 +152,12 @@ func CreateTest(root interface{}) *Application {
 }
 
 func (a *Application) init() *Application {
-	return a.loadElem(a.Logger, *new(reflect.StructTag)).loadElem(a, *new(reflect.StructTag)).loadConfigFile()
+	a.loadElem(a.Logger, *new(reflect.StructTag)).loadElem(a, *new(reflect.StructTag)).loadElem(a.Publisher, *new(reflect.StructTag)).loadElem(a.Watcher, *new(reflect.StructTag))
+	a.setSource(reflect.TypeOf(a.Logger), ``, SourceApplication)
+	a.setSource(reflect.TypeOf(a), ``, SourceApplication)
+	a.setSource(reflect.TypeOf(a.Publisher), ``, SourceApplication)
+	a.setSource(reflect.TypeOf(a.Watcher), ``, SourceApplication)
+	return a.loadConfigFile()
 }
 
 func (a *Application) loadElem(elem interface{}, tag reflect.StructTag) *Application {
@@ -98,13 +170,24 @@ func (a *Application) load(fieldType reflect.Type, Value reflect.Value, tag refl
 	name := GetBeanName(fieldType, tag)
 	a.Logger.Debug("%s -> %s", name, Value.Type())
 	newBean := NewBean(Value, tag)
+	newBean.Name = name
+	newBean.Type = fieldType
+	newBean.Namespace = a.namespace
 	a.BeanMap[fieldType][name] = newBean
 	return a
 }
 
+// setSource set Source of the bean with fieldType and name in tag, when it is not set
+func (a *Application) setSource(fieldType reflect.Type, tag reflect.StructTag, source string) {
+	if bean, ok := a.BeanMap[fieldType][GetBeanName(fieldType, tag)]; ok && bean.Source == "" {
+		bean.Source = source
+	}
+}
+
 func (a *Application) Prepare() *Application {
 	a.loadPrimes()
 	a.loadMethodBeanIn()
+	a.prepared = true
 	return a
 }
 
@@ -126,6 +209,7 @@ func (a *Application) setTests(testType reflect.Type, testValue reflect.Value) {
 
 func (a *Application) setTest(testType reflect.Type, testValue reflect.Value, Tag reflect.StructTag) {
 	a.LoadBean(testType, testValue, Tag)
+	a.setSource(testType, Tag, SourceRoot)
 	a.TestingBeans = append(a.TestingBeans, NewTestingBean(testType, testValue))
 	a.Logger.Debug("SetTest: %s", testType)
 }
@@ -149,21 +233,168 @@ Then, we load BeanMethodOut, which mean result of "bean method", however, what's
 
 And then, we load normal bean recursively
 
+Run blocks until SIGINT or SIGTERM is received, or server stops, or a worker failed, then shutdown the app
+
+Run log errors and exit when Build failed, or server or a worker failed, use Start or Build to handle errors yourself
+
+Run and Start parse os.Args[1:] by Args before Build, when Args isn't called
+
 */
 func (a *Application) Run() {
+	if _, err := a.parseArgs().Build(); err != nil {
+		a.Logger.Critical(err.Error())
+		os.Exit(1)
+	}
+	if err := a.serve(); err != nil {
+		a.Logger.Critical(err.Error())
+		os.Exit(1)
+	}
+}
+
+// Start build the app and serve, blocks until shutdown, return error when server or a worker failed
+func (a *Application) Start() error {
+	if _, err := a.parseArgs().Build(); err != nil {
+		return err
+	}
+	return a.serve()
+}
+
+/*
+Build load and assemble all beans, call factories and init hooks
+
+return BootErrors when there are any problems in bootstrap
+*/
+func (a *Application) Build() (*Application, error) {
 	a.Prepare()
+	return a, a.build()
+}
+
+func (a *Application) build() error {
+	a.Server.Use(a.requestScope)
 	a.loadBeanChild()
 	a.assemble()
+	a.checkCycles()
+	if err := a.BootError(); err != nil {
+		return err
+	}
+	a.preInit()
 	a.CallFactory()
 	a.bindFactoryWithValue()
-	a.Server.Start(*a.Addr)
+	if err := a.BootError(); err != nil {
+		return err
+	}
+	a.postInit()
+	if err := a.BootError(); err != nil {
+		return err
+	}
+	tasks := a.loadTasks(a.dependencyOrder())
+	if err := a.BootError(); err != nil {
+		return err
+	}
+	a.Publisher.Subscribe(a.dependencyOrder())
+	if a.StartupReport != nil && *a.StartupReport {
+		a.Logger.Info("Beans:\n%s", a.Describe().Table())
+	}
+	a.Publisher.Publish(&StartedEvent{a})
+	a.Scheduler.Schedule(tasks)
+	a.workers = NewWorkers(a.runnables(), a.Logger)
+	return nil
+}
+
+func (a *Application) serve() (err error) {
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- a.Server.Start(*a.Addr)
+	}()
+	a.Scheduler.Start()
+	a.workers.Start()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case err = <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			a.Logger.Info("Server closed")
+			err = nil
+		} else {
+			a.Logger.Error("Server stopped: %s", err)
+		}
+	case err = <-a.workers.Failed:
+		a.Logger.Error("Worker failed, shutting down")
+	case sig := <-signals:
+		a.Logger.Info("Receive %s, shutting down", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.GetShutdownTimeout())
+	defer cancel()
+	if shutdownErr := a.Shutdown(ctx); shutdownErr != nil {
+		a.Logger.Error("Shutdown failed: %s", shutdownErr)
+		if err == nil {
+			err = shutdownErr
+		}
+	}
+	return
+}
+
+// GetShutdownTimeout parse ShutdownTimeout, return DefaultShutdownTimeout when it is invalid
+func (a *Application) GetShutdownTimeout() time.Duration {
+	if a.ShutdownTimeout != nil {
+		timeout, err := time.ParseDuration(*a.ShutdownTimeout)
+		if err == nil {
+			return timeout
+		}
+		a.Logger.Error("Shutdown timeout '%s' invalid, use %s", *a.ShutdownTimeout, DefaultShutdownTimeout)
+	}
+	return DefaultShutdownTimeout
+}
+
+/*
+Shutdown drain the echo server, stop scheduled tasks and workers, then destroy beans in reverse dependency order
+
+every step runs even if the former one fails, errors of all steps are joined;
+beans are not destroyed any more when ctx is done
+
+it runs only once, later calls wait for the first one and return its result
+*/
+func (a *Application) Shutdown(ctx context.Context) error {
+	a.shutdownOnce.Do(func() {
+		a.shutdownErr = a.shutdown(ctx)
+	})
+	return a.shutdownErr
+}
+
+func (a *Application) shutdown(ctx context.Context) error {
+	errs := make([]error, 0)
+	a.Publisher.Publish(&ShutdownEvent{a})
+	if err := a.Server.Server.Shutdown(ctx); err != nil {
+		a.Logger.Error("Server doesn't drain: %s", err)
+		errs = append(errs, err)
+	} else {
+		a.Logger.Info("Server drained")
+	}
+	if err := a.Scheduler.Stop(ctx); err != nil {
+		a.Logger.Error("Scheduled tasks don't return: %s", err)
+		errs = append(errs, err)
+	}
+	if a.workers != nil {
+		if err := a.workers.Stop(ctx); err != nil {
+			a.Logger.Error("Workers don't return: %s", err)
+			errs = append(errs, err)
+		}
+	}
+	a.Publisher.Wait()
+	if err := a.destroy(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
 
 func (a *Application) Test(t *testing.T) *Application {
-	a.loadBeanChild()
-	a.assemble()
-	a.CallFactory()
-	a.bindFactoryWithValue()
+	if err := a.build(); err != nil {
+		t.Fatal(err)
+	}
 	a.runTestCase(t)
 	return a
 }
@@ -205,22 +436,49 @@ func (a *Application) loadPrimes() {
 	root := a.Root
 	rootType := reflect.TypeOf(root).Elem()
 	for i := 0; i < rootType.NumField(); i++ {
-		field := rootType.Field(i)
-		if CheckConfiguration(field) {
+		a.loadPrimeField(rootType.Field(i), "/")
+	}
+	a.loadOnMissing()
+}
+
+// loadPrimeField load a field of Root or Module, prefix is the route prefix of it
+func (a *Application) loadPrimeField(field reflect.StructField, prefix string) {
+	if CheckModule(field) {
+		a.loadConditionally(field.Tag, field.Type.String(), func() {
+			a.loadModule(field, prefix)
+		})
+	} else if CheckConfiguration(field) {
+		a.loadConditionally(field.Tag, field.Type.String(), func() {
 			a.loadConfiguration(field)
-		} else if CheckEntities(field) {
-			a.loadEntities(field)
-		} else {
-			a.loadWebField(field, "/")
-		}
+		})
+	} else if CheckEntities(field) {
+		a.loadEntities(field)
+	} else {
+		a.loadWebField(field, prefix)
 	}
 }
 
-// load children of a Bean
+// load children of a Bean, until no more new bean is loaded
 func (a *Application) loadBeanChild() {
-	for fileType, beanMap := range a.BeanMap {
-		if len(beanMap) > 0 {
-			a.RecursivelyLoad(fileType)
+	loaded := make(map[reflect.Type]bool)
+	for {
+		pending := make([]reflect.Type, 0)
+		for _, bean := range a.sortedBeans() {
+			if !loaded[bean.Type] {
+				loaded[bean.Type] = true
+				pending = append(pending, bean.Type)
+			}
+		}
+		if len(pending) == 0 {
+			for _, bean := range a.sortedBeans() {
+				if bean.Source == "" {
+					bean.Source = SourceRecursive
+				}
+			}
+			return
+		}
+		for _, fieldType := range pending {
+			a.RecursivelyLoad(fieldType)
 		}
 	}
 }
@@ -262,6 +520,7 @@ func (a *Application) loadCtrl(field reflect.StructField, prefix string) {
 	Value := reflect.New(fieldType.Elem()).Elem()
 	a.CtrlBeanMap[prefix] = NewCtrlBean(Value, field.Tag, Name)
 	a.LoadPrimeBean(fieldType, Value, ``)
+	a.setSource(fieldType, ``, SourceRoot)
 
 	for i := 0; i < fieldType.Elem().NumField(); i++ {
 		child := fieldType.Elem().Field(i)
@@ -311,6 +570,7 @@ func (a *Application) loadMiddleware(field reflect.StructField, prefix string) {
 	Value := reflect.New(fieldType.Elem()).Elem()
 	a.MdWareBeanMap[newPrefix] = NewMdWareBean(Value, field.Tag, Name)
 	a.LoadPrimeBean(fieldType, Value, ``)
+	a.setSource(fieldType, ``, SourceRoot)
 
 	for i := 0; i < fieldType.NumMethod(); i++ {
 		method := Value.Addr().Method(i)
@@ -346,18 +606,26 @@ func (a *Application) loadFile(field reflect.StructField, prefix string) {
 func (a *Application) loadConfiguration(config reflect.StructField) {
 	configValue := reflect.New(config.Type.Elem()).Elem() // save Elem in Bean
 	a.LoadBean(config.Type, configValue, config.Tag)
+	a.setSource(config.Type, config.Tag, SourceConfiguration)
 	for i := 0; i < configValue.NumField(); i++ {
 		fieldValue := configValue.Field(i)
 		field := config.Type.Elem().Field(i)
 		if CheckConfiguration(field) {
-			a.LoadPrimeBean(field.Type, fieldValue, field.Tag)
+			a.loadConditionally(field.Tag, field.Type.String(), func() {
+				a.LoadPrimeBean(field.Type, fieldValue, field.Tag)
+				a.setSource(field.Type, field.Tag, SourceConfiguration)
+			})
 		}
 	}
 
 	a.Logger.Debug("Load Configuration: %s", config.Type)
+	conditions := GetMethodConditions(config.Type.Elem())
 	for i := 0; i < configValue.Addr().NumMethod(); i++ {
 		name := configValue.Addr().Type().Method(i).Name
-		a.loadBeanMethodOut(configValue.Addr().MethodByName(name), name)
+		method := configValue.Addr().MethodByName(name)
+		a.loadConditionally(conditions[name], name, func() {
+			a.loadBeanMethodOut(method, name)
+		})
 	}
 }
 
@@ -385,49 +653,68 @@ if a normal bean doesn't a name
 	3. there is no loaded bean with the same type, this method with initialize a new bean
 */
 func (a *Application) LoadBean(fieldType reflect.Type, fieldValue reflect.Value, tag reflect.StructTag) *Application {
+	return a.loadBean(fieldType, fieldValue, tag, "")
+}
+
+// loadBean is LoadBean with path of the field, for error reporting
+func (a *Application) loadBean(fieldType reflect.Type, fieldValue reflect.Value, tag reflect.StructTag, fieldPath string) *Application {
 	name := tag.Get("name")
 	if name == "" {
 		if ConfirmSameTypeInMap(a.BeanMap, fieldType) {
 			if len(a.BeanMap[fieldType]) > 1 {
-				a.Logger.Critical("There are more than one %s, please named it.", fieldType)
-				os.Exit(1)
+				a.addError(NewBeanError(AmbiguousBean, fieldType, name, fieldPath, "there are more than one bean of this type, please name it"))
 			}
 		} else {
 			a.load(fieldType, fieldValue, tag)
 		}
 	} else {
 		if !ConfirmBeanInMap(a.BeanMap, fieldType, name) {
-			a.Logger.Critical("There are no %s named %s, please define it in config.", fieldType, name)
-			os.Exit(1)
+			a.addError(NewBeanError(MissingBean, fieldType, name, fieldPath, "there is no bean with this name, please define it in config"))
 		}
 	}
 	return a
 }
 
+/*
+loadBeanMethodOut load result of a factory, a factory returns a component, or a component and an error
+
+params of factory are components, or structs with value fields, see CheckValueParam
+*/
 func (a *Application) loadBeanMethodOut(method reflect.Value, name string) {
 	methodType := method.Type()
-	if methodType.NumOut() == 1 {
-		fieldType := methodType.Out(0)
-		if fieldType.Kind() == reflect.Ptr && ContainsFields(fieldType.Elem(), ComponentTypes) {
-			a.Logger.Debug("%s -> %s", name, methodType)
-			methodBean := NewBeanMethod(method, name)
-			a.Logger.Debug("Method: %s", name)
-			OutValue := reflect.New(fieldType.Elem()).Elem()
-			if a.LoadPrimeBean(fieldType, OutValue, GetTagFromName(name)) {
-				for i := 0; i < methodType.NumIn(); i++ {
-					inType := methodType.In(i)
-					if CheckFieldPtr(inType) && ContainsFields(inType.Elem(), ComponentTypes) {
-						methodBean.Ins = append(methodBean.Ins, inType)
-					} else {
-						a.Logger.Errorf(`Param %s of %s isn't one of ComponentTypes`, inType, name)
-						os.Exit(1)
-					}
-				}
-				methodBean.OutValue = OutValue
-				a.BeanMethodMap[fieldType] = map[string]*Method{name: methodBean}
+	if CheckFactory(methodType) && ContainsFields(methodType.Out(0).Elem(), ComponentTypes) {
+		a.loadFactory(method, name)
+	}
+}
+
+// loadFactory load result of a factory as a prime bean with name, method should be a factory, see CheckFactory, return nil when it is not loaded
+func (a *Application) loadFactory(method reflect.Value, name string) *Method {
+	methodType := method.Type()
+	fieldType := methodType.Out(0)
+	a.Logger.Debug("%s -> %s", name, methodType)
+	methodBean := NewBeanMethod(method, name)
+	a.Logger.Debug("Method: %s", name)
+	OutValue := reflect.New(fieldType.Elem()).Elem()
+	if a.loadPrime(fieldType, OutValue, GetTagFromName(name)) {
+		for i := 0; i < methodType.NumIn(); i++ {
+			inType := methodType.In(i)
+			if CheckFieldPtr(inType) && ContainsFields(inType.Elem(), ComponentTypes) || CheckValueParam(inType) {
+				methodBean.Ins = append(methodBean.Ins, inType)
+			} else {
+				a.addError(NewBeanError(BadFactory, inType, name, GetParamPath(name, i), "param is neither one of ComponentTypes nor struct with value fields"))
 			}
 		}
+		methodBean.OutValue = OutValue
+		methodBean.Out = a.BeanMap[fieldType][name]
+		methodBean.Out.Factory = methodBean
+		methodBean.Out.Source = SourceFactory
+		if a.BeanMethodMap[fieldType] == nil {
+			a.BeanMethodMap[fieldType] = make(map[string]*Method)
+		}
+		a.BeanMethodMap[fieldType][name] = methodBean
+		return methodBean
 	}
+	return nil
 }
 
 /*
@@ -437,19 +724,22 @@ load field in configuration and out of beanMethod in configuration
 */
 func (a *Application) LoadPrimeBean(fieldType reflect.Type, fieldValue reflect.Value, tag reflect.StructTag) bool {
 	if ContainsFields(fieldType.Elem(), ComponentTypes) {
-		tag := tag
-		name := GetBeanName(fieldType, tag)
-		if ConfirmAddBeanMap(a.BeanMap, fieldType, name) {
-			a.load(fieldType, fieldValue, tag)
-			return true
-		} else {
-			a.Logger.Errorf("There too many %s named %s in Application", fieldType, name)
-			os.Exit(1)
-		}
+		return a.loadPrime(fieldType, fieldValue, tag)
 	}
 	return false
 }
 
+// loadPrime load a prime bean of any Ptr of struct, collect a BeanError when the name is used
+func (a *Application) loadPrime(fieldType reflect.Type, fieldValue reflect.Value, tag reflect.StructTag) bool {
+	name := GetBeanName(fieldType, tag)
+	if ConfirmAddBeanMap(a.BeanMap, fieldType, name) {
+		a.load(fieldType, fieldValue, tag)
+		return true
+	}
+	a.addError(NewBeanError(DuplicateBean, fieldType, name, "", "there are more than one prime bean with this name"))
+	return false
+}
+
 /*
 LoadBeanAndRecursion initialize a instance of a type and load it
 
@@ -463,7 +753,7 @@ then recursively load children of this type
 /*
 RecursivelyLoad recursively load children of a normal bean
 
-only if there is no prime bean among the children
+only if there is no prime bean among the children, optional children are never loaded
 */
 func (a *Application) RecursivelyLoad(fieldType reflect.Type) {
 	if CheckFieldPtr(fieldType) && ContainsFields(fieldType.Elem(), ComponentTypes) {
@@ -471,14 +761,15 @@ func (a *Application) RecursivelyLoad(fieldType reflect.Type) {
 		if appType.Kind() == reflect.Struct {
 			for i := 0; i < appType.NumField(); i++ {
 				field := appType.Field(i)
-				if CheckComponents(field) {
-					a.LoadBean(field.Type, reflect.New(field.Type.Elem()).Elem(), field.Tag)
+				if CheckComponents(field) && !IsOptional(field) {
+					a.loadBean(field.Type, reflect.New(field.Type.Elem()).Elem(), field.Tag, GetFieldPath(fieldType, field))
 				}
 			}
 		}
 	}
 }
 
+// GetRealConfigPathAndType return path and type of the base config file in CONF tag of Root, overlays of modes are not included
 func (a *Application) GetRealConfigPathAndType() (string, string) {
 	appType := reflect.TypeOf(a.Root).Elem()
 	for i := 0; i < appType.NumField(); i++ {
@@ -494,17 +785,15 @@ func (a *Application) GetRealConfigPathAndType() (string, string) {
 				a.Logger.Info("Conf file path unexpected, use %s", path)
 			}
 
-			path = GetConfigFileByMode(path)
-
 			if fileType != "" {
-				if fileType == JSON || fileType == YAML {
+				if _, ok := ConfigDecoders[fileType]; ok {
 					return path, fileType
 				}
-				a.Logger.Info("Conf file suffix .%s unexpected, use default type", fileType, DefaultConfType)
+				a.Logger.Info("Conf file type %s unexpected, use type of suffix or %s", fileType, DefaultConfType)
 			}
 
-			if strings.HasSuffix(path, JSON) {
-				return path, JSON
+			if suffixType, ok := GetConfigType(path); ok {
+				return path, suffixType
 			}
 
 			return path, DefaultConfType
@@ -515,20 +804,67 @@ func (a *Application) GetRealConfigPathAndType() (string, string) {
 	return DefaultPath, DefaultConfType
 }
 
-func (a *Application) loadConfigFile() *Application {
+// GetConfigPathsAndType return the base config file and its overlays of modes in order, see GetModes
+func (a *Application) GetConfigPathsAndType() ([]string, string) {
 	path, fileType := a.GetRealConfigPathAndType()
-	config, err := GetJSONFromAnyFile(path, fileType)
+	paths := []string{path}
+	for _, mode := range GetModes() {
+		paths = append(paths, GetConfigFileOfMode(path, mode))
+	}
+	return paths, fileType
+}
+
+// GetActiveConfigPath return path of the overlay of the last mode in RADY_MODE, which takes precedence, or the base config file when RADY_MODE is unset
+func (a *Application) GetActiveConfigPath() string {
+	paths, _ := a.GetConfigPathsAndType()
+	return paths[len(paths)-1]
+}
+
+/*
+readConfigFile read the base config file and merge overlays of modes on it in order, see MergeJSON,
+and placeholders in the merged config are resolved, see placeholders
+
+files don't exist are skipped when there are overlays, but at least one of them should exist
+*/
+func (a *Application) readConfigFile() (string, error) {
+	paths, fileType := a.GetConfigPathsAndType()
+	config, loaded := "", false
+	for _, path := range paths {
+		content, err := GetJSONFromAnyFile(path, fileType)
+		if os.IsNotExist(err) && len(paths) > 1 {
+			a.Logger.Debug("Skip %s: file doesn't exist", path)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", path, err)
+		}
+		if loaded {
+			if content, err = MergeJSON(config, content); err != nil {
+				return "", fmt.Errorf("%s: %s", path, err)
+			}
+		}
+		config, loaded = content, true
+		a.Logger.Debug("Load %s(%s)", path, fileType)
+	}
+	if !loaded {
+		return "", fmt.Errorf("none of %v exists", paths)
+	}
+	return a.interpolate(config)
+}
+
+func (a *Application) loadConfigFile() *Application {
+	config, err := a.readConfigFile()
 	if err == nil {
 		a.ConfigFile = config
-		a.Logger.Debug("Load %s(%s)", path, fileType)
 	} else {
-		a.Logger.Error("File %s load failed, %s", path, err.Error())
+		a.Logger.Error("Config file load failed, %s", err.Error())
 	}
 	return a
 }
 
+// WriteConfigFile write value into the config file by GetActiveConfigPath, it is merged on the base config file when it is an overlay
 func (a *Application) WriteConfigFile(value string) error {
-	path, _ := a.GetRealConfigPathAndType()
+	path := a.GetActiveConfigPath()
 	return ioutil.WriteFile(path, []byte(value), os.ModeAppend)
 }
 
@@ -552,18 +888,51 @@ func (a *Application) registerCtrl(handlerFunc HandlerFunc, method reflect.Type,
 	}
 }
 
+// assemble inject fields of normal beans, beans returned by factories are replaced when factories are called
 func (a *Application) assemble() {
-	for beanType, nameMap := range a.BeanMap {
-		for name, bean := range nameMap {
-			Value := bean.Value
-			for i := 0; i < beanType.Elem().NumField(); i++ {
-				child := beanType.Elem().Field(i)
-				if CheckComponents(child) {
-					a.assembleBean(name, beanType.String(), Value.Field(i), child)
-				} else if CheckValues(child) {
-					a.assembleValue(name, beanType.String(), Value.Field(i), child)
-				}
-			}
+	a.assembleBeans(a.sortedBeans())
+}
+
+/*
+assembleBeans inject fields of prototype and request beans first, to check dependencies of them
+
+then inject fields and factory params of singleton beans, prototype and request beans are instantiated when injected
+*/
+func (a *Application) assembleBeans(beans []*Bean) {
+	for _, bean := range beans {
+		if bean.Factory == nil && IsScoped(bean) {
+			a.assembleFields(bean)
+		}
+	}
+	if !a.checkPrototypeCycles(beans) {
+		return
+	}
+	for _, bean := range beans {
+		if IsScoped(bean) {
+			continue
+		}
+		if bean.Factory == nil {
+			a.assembleFields(bean)
+		} else {
+			a.assembleParams(bean)
+		}
+	}
+}
+
+func (a *Application) assembleFields(bean *Bean) {
+	beanType, Value := bean.Type, bean.Value
+	for i := 0; i < beanType.Elem().NumField(); i++ {
+		child := beanType.Elem().Field(i)
+		if CheckComponents(child) {
+			a.assembleBean(bean, Value.Field(i), child)
+		} else if CheckInterfaceComponents(child) {
+			a.assembleInterface(bean, Value.Field(i), child)
+		} else if CheckCollectionComponents(child) {
+			a.assembleCollection(bean, Value.Field(i), child)
+		} else if CheckProviderComponents(child) {
+			a.assembleProvider(bean, Value.Field(i), child)
+		} else if CheckValues(child) {
+			a.assembleValue(bean, Value.Field(i), child)
 		}
 	}
 }
@@ -576,64 +945,97 @@ func (a *Application) logAssembleValue(motherName, motherType, value, fieldName
 	a.Logger.Debug("Field %s of [%s][%s] set value &(%s)", fieldName, motherType, motherName, value)
 }
 
-func (a *Application) assembleBean(motherName, motherType string, value reflect.Value, field reflect.StructField) {
+func (a *Application) assembleBean(mother *Bean, value reflect.Value, field reflect.StructField) {
+	fieldPath := GetFieldPath(mother.Type, field)
+	bean, err := a.resolveBean(field, fieldPath)
+	if err != nil {
+		if err.Kind != MissingBean || !IsOptional(field) {
+			a.addError(err)
+		}
+		return
+	}
+	a.injectBean(mother, bean, value, field, fieldPath)
+}
+
+// resolveBean find the bean to inject into a Ptr field
+func (a *Application) resolveBean(field reflect.StructField, fieldPath string) (*Bean, *BeanError) {
 	if len(a.BeanMap[field.Type]) == 0 {
-		a.Logger.Critical("Type %s doesn't exist in beanMap !!!", field.Type)
-		os.Exit(1)
+		return nil, NewBeanError(MissingBean, field.Type, field.Tag.Get("name"), fieldPath, "type doesn't exist in BeanMap")
 	}
 
 	if len(a.BeanMap[field.Type]) == 1 {
-		for name, bean := range a.BeanMap[field.Type] {
-			value.Set(bean.Value.Addr())
-			a.logAssembleBean(motherName, motherType, name, field.Type.String(), field.Name)
+		for _, bean := range a.BeanMap[field.Type] {
+			return bean, nil
 		}
 	}
 
-	if len(a.BeanMap[field.Type]) > 1 {
-		name := GetBeanName(field.Type, field.Tag)
-		if bean, ok := a.BeanMap[field.Type][name]; ok {
-			value.Set(bean.Value.Addr())
-			a.logAssembleBean(motherName, motherType, name, field.Type.String(), field.Name)
-		} else {
-			a.Logger.Critical("Bean [%s][%s] doesn't exist in beanMap !!!", field.Type, name)
-			os.Exit(1)
-		}
+	name := GetBeanName(field.Type, field.Tag)
+	if bean, ok := a.BeanMap[field.Type][name]; ok {
+		return bean, nil
+	} else if aliasName := field.Tag.Get("name"); aliasName == "" {
+		return nil, NewBeanError(AmbiguousBean, field.Type, aliasName, fieldPath, "there are more than one bean of this type, please name it")
+	} else {
+		return nil, NewBeanError(MissingBean, field.Type, aliasName, fieldPath, "there is no bean with this name, please define it in config")
+	}
+}
+
+func (a *Application) injectBean(mother, bean *Bean, value reflect.Value, field reflect.StructField, fieldPath string) {
+	bean, err := a.scopedBean(mother, bean, fieldPath)
+	if err != nil {
+		a.addError(err)
+		return
 	}
+	dep := NewDependency(bean, fieldPath, false)
+	dep.Field = value
+	dep.Inject(bean.Expose(value.Type()))
+	mother.Deps = append(mother.Deps, dep)
+	a.logAssembleBean(mother.Name, mother.Type.String(), bean.Name, bean.Type.String(), field.Name)
 }
 
-func (a *Application) assembleValue(motherName, motherType string, value reflect.Value, field reflect.StructField) {
-	key := strings.Trim(field.Tag.Get("value"), " ")
+func (a *Application) assembleValue(mother *Bean, value reflect.Value, field reflect.StructField) {
+	motherName, motherType := mother.Name, mother.Type
+	key := ResolveKey(mother.Namespace, strings.Trim(field.Tag.Get("value"), " "))
 	if key == "" {
 		return
 	}
 	defaultValue := field.Tag.Get("default")
 	if valueBean, ok := a.ValueBeanMap[key]; ok {
 		if !valueBean.SetValue(value, field.Type) {
-			a.Logger.Error("Unknow Type: %s", field.Type)
+			a.addError(NewBeanError(UnknownValueType, field.Type, key, GetFieldPath(motherType, field), "type of value field is unknown"))
 		}
-		a.logAssembleValue(motherName, motherType, valueBean.Value.String(), field.Name)
+		a.logAssembleValue(motherName, motherType.String(), valueBean.Value.String(), field.Name)
 		return
 	}
 
-	newValue := gjson.Get(a.ConfigFile, key)
+	env := strings.Trim(field.Tag.Get("env"), " ")
+	newValue := a.lookupValue(key, env)
 	trueDefault := gjson.Get(fmt.Sprintf(`{"default": "%s"}`, defaultValue), "default")
+	if moduleDefault, ok := a.Defaults[key]; ok {
+		trueDefault = moduleDefault
+	}
 	if !newValue.Exists() {
 		newValue = trueDefault
 	}
 	valueBean := NewValueBean(newValue, key, trueDefault)
+	valueBean.Env = env
 	if !valueBean.SetValue(value, field.Type) {
-		a.Logger.Error("Unknow Type: %s", field.Type)
+		a.addError(NewBeanError(UnknownValueType, field.Type, key, GetFieldPath(motherType, field), "type of value field is unknown"))
 	}
 
 	a.ValueBeanMap[key] = valueBean
-	a.logAssembleValue(motherName, motherType, valueBean.Value.String(), field.Name)
+	a.logAssembleValue(motherName, motherType.String(), valueBean.Value.String(), field.Name)
 }
 
+// CallFactory call factories in dependency order, a factory is called after factories of its params
 func (a *Application) CallFactory() {
-	for outType, methodMap := range a.BeanMethodMap {
-		for name, method := range methodMap {
-			method.Call(a)
-			a.Logger.Debug("Call factory %s -> %s", name, outType)
+	for _, bean := range a.dependencyOrder() {
+		if method := bean.Factory; method != nil {
+			if err := method.Call(a); err != nil {
+				a.addError(NewFactoryError(bean, err))
+				continue
+			}
+			a.Logger.Debug("Call factory %s -> %s", method.Name, bean.Type)
+			a.callPreInit(bean)
 		}
 	}
 }
@@ -658,11 +1060,11 @@ func (a *Application) recursivelyBind(fieldType reflect.Type, method *Method, ch
 				a.recursivelyBind(child.Type, method, checkedMap)
 			}
 		} else if CheckPtrValues(child) {
-			key := strings.Trim(child.Tag.Get("value"), " ")
+			key := ResolveKey(a.namespaceOf(fieldType), strings.Trim(child.Tag.Get("value"), " "))
 			valueBean, ok := a.ValueBeanMap[key]
 			if !ok {
-				a.Logger.Critical("Ignored value '%s' !!!", key)
-				os.Exit(1)
+				a.addError(NewBeanError(MissingValue, child.Type, key, GetFieldPath(fieldType, child), "value is ignored"))
+				continue
 			}
 
 			if _, ok = valueBean.MethodSet[method]; ok {
@@ -674,14 +1076,202 @@ func (a *Application) recursivelyBind(fieldType reflect.Type, method *Method, ch
 	}
 }
 
-func (a *Application) ReloadValues() {
-	a.loadConfigFile()
-	a.FactoryToRecall = make(map[*Method]bool)
-	for _, valueBean := range a.ValueBeanMap {
-		valueBean.Reload(a)
+// namespaceOf return Namespace of the bean with fieldType, empty when there is not only one
+func (a *Application) namespaceOf(fieldType reflect.Type) string {
+	if len(a.BeanMap[fieldType]) != 1 {
+		return ""
+	}
+	for _, bean := range a.BeanMap[fieldType] {
+		return bean.Namespace
 	}
+	return ""
+}
+
+/*
+ReloadValues reload config file, reset values and recall factories bound with values
+
+the config file is parsed and validated first, then changed values are staged and factories are recalled with them into staged results,
+values are reset and results are set into beans only when all factories succeed;
+when the file is invalid, or a factory returns an error or panics, staged results are destroyed,
+nothing of the app is changed and a BeanError of ReloadFailed is returned
+
+reloads are serialized and requests are not blocked, changes are committed at once under lazyLock,
+so beans loaded lazily or instantiated in requests never see values half reloaded,
+but a request in progress may read old values before the commit and new ones after it
+
+keys changed and factories recalled are logged, and published by ReloadedEvent after committed
+*/
+func (a *Application) ReloadValues() error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+	path, _ := a.GetRealConfigPathAndType()
+	config, err := a.readConfigFile()
+	if err == nil && !gjson.Valid(config) {
+		err = fmt.Errorf("invalid json")
+	}
+	if err != nil {
+		reloadErr := NewBeanError(ReloadFailed, reflect.TypeOf(a), path, "", fmt.Sprintf("config file cannot be parsed: %s", err))
+		reloadErr.Err = err
+		a.Logger.Error(reloadErr.Error())
+		return reloadErr
+	}
+
+	a.lazyLock.Lock()
+	valueBeans, order := make(map[string]*ValueBean), a.dependencyOrder()
+	for key, valueBean := range a.ValueBeanMap {
+		valueBeans[key] = valueBean
+	}
+	a.lazyLock.Unlock()
+
+	stage := newReloadStage()
+	event := &ReloadedEvent{App: a, Keys: make([]string, 0), Factories: make([]string, 0)}
+	for key, valueBean := range valueBeans {
+		if result := valueBean.lookup(a, config); valueBean.changed(result) {
+			a.Logger.Debug("Stage Value '%s' to %s", key, result.String())
+			stage.stageValue(valueBean, result)
+			event.Keys = append(event.Keys, key)
+		}
+	}
+	sort.Strings(event.Keys)
 
-	for recallFactory := range a.FactoryToRecall {
-		recallFactory.Call(a)
+	for _, bean := range order {
+		recallFactory := bean.Factory
+		if recallFactory == nil || !stage.factories[recallFactory] && (bean.Template == nil || !stage.factories[bean.Template.Factory]) {
+			continue
+		}
+		result, err := a.recall(recallFactory, stage.params(recallFactory))
+		if err != nil {
+			a.destroyBeans(context.Background(), stage.stagedBeans())
+			reloadErr := NewBeanError(ReloadFailed, bean.Type, bean.Name, "", fmt.Sprintf("recall factory %s failed: %s", recallFactory.Name, err))
+			reloadErr.Err = err
+			a.Logger.Error("%s, reload is rolled back", reloadErr)
+			return reloadErr
+		}
+		stage.stageResult(bean, result)
+		event.Factories = append(event.Factories, recallFactory.Name)
+	}
+
+	a.lazyLock.Lock()
+	a.ConfigFile = config
+	stage.commit()
+	a.lazyLock.Unlock()
+	a.Logger.Info("Reload config: keys %v changed, factories %v recalled", event.Keys, event.Factories)
+	a.Publisher.Publish(event)
+	return nil
+}
+
+// recall call the factory with params in reload, a panic of the factory is recovered as an error
+func (a *Application) recall(factory *Method, params []reflect.Value) (result reflect.Value, err error) {
+	defer func() {
+		if cause := recover(); cause != nil {
+			err = fmt.Errorf("panic: %v", cause)
+		}
+	}()
+	return factory.call(params)
+}
+
+/*
+reloadStage is changes of a reload, they are kept off the app until committed
+
+values is new values of changed ValueBeans, storage is copies of ValueBeans with new values, found by Ptr to values of old ones
+
+factories is factories bound with changed values, results is their results found by Ptr of the beans they are recalled for
+
+copies is Parameters copied for recalled factories, found by Ptr of the original ones
+*/
+type reloadStage struct {
+	values    map[*ValueBean]gjson.Result
+	storage   map[interface{}]*ValueBean
+	factories map[*Method]bool
+	recalled  []*Bean
+	results   map[interface{}]reflect.Value
+	copies    map[interface{}]reflect.Value
+}
+
+// newReloadStage is factory function of reloadStage
+func newReloadStage() *reloadStage {
+	return &reloadStage{
+		values:    make(map[*ValueBean]gjson.Result),
+		storage:   make(map[interface{}]*ValueBean),
+		factories: make(map[*Method]bool),
+		recalled:  make([]*Bean, 0),
+		results:   make(map[interface{}]reflect.Value),
+		copies:    make(map[interface{}]reflect.Value),
+	}
+}
+
+// stageValue stage the new result of valueBean, and mark factories bound with it to recall
+func (s *reloadStage) stageValue(valueBean *ValueBean, result gjson.Result) {
+	s.values[valueBean] = result
+	staged := NewValueBean(result, valueBean.Key, valueBean.Default)
+	for _, value := range valueBean.ValueMap {
+		s.storage[value.Addr().Interface()] = staged
+	}
+	for method := range valueBean.MethodSet {
+		s.factories[method] = true
+	}
+}
+
+// stageResult stage result of the factory recalled for bean
+func (s *reloadStage) stageResult(bean *Bean, result reflect.Value) {
+	s.recalled = append(s.recalled, bean)
+	s.results[bean.Value.Addr().Interface()] = result
+}
+
+// params return params to recall the factory, recalled beans are replaced with their staged results and Parameters are copied with staged values
+func (s *reloadStage) params(factory *Method) []reflect.Value {
+	params := make([]reflect.Value, 0, len(factory.InValues))
+	for _, value := range factory.InValues {
+		params = append(params, s.param(value.Addr()))
+	}
+	return params
+}
+
+// param return staged result of a recalled bean, or a copy of Parameter whose values and recalled beans are staged, or param itself
+func (s *reloadStage) param(param reflect.Value) reflect.Value {
+	if result, ok := s.results[param.Interface()]; ok {
+		return result
+	}
+	if staged, ok := s.copies[param.Interface()]; ok {
+		return staged
+	}
+	if !ContainsField(param.Type().Elem(), Parameter{}) {
+		return param
+	}
+
+	staged := reflect.New(param.Type().Elem())
+	staged.Elem().Set(param.Elem())
+	s.copies[param.Interface()] = staged
+	for i := 0; i < staged.Elem().NumField(); i++ {
+		field, value := param.Type().Elem().Field(i), staged.Elem().Field(i)
+		if !value.CanSet() || value.Kind() != reflect.Ptr || value.IsNil() {
+			continue
+		}
+		if valueBean, ok := s.storage[value.Interface()]; ok {
+			valueBean.SetValue(value, field.Type)
+		} else if CheckPtrOfStruct(field.Type) {
+			value.Set(s.param(value))
+		}
+	}
+	return staged
+}
+
+// stagedBeans return beans of staged results in order they are recalled
+func (s *reloadStage) stagedBeans() []*Bean {
+	beans := make([]*Bean, 0, len(s.recalled))
+	for _, bean := range s.recalled {
+		beans = append(beans, &Bean{Name: bean.Name, Type: bean.Type, Value: s.results[bean.Value.Addr().Interface()].Elem()})
+	}
+	return beans
+}
+
+// commit reset staged values and set staged results into beans
+func (s *reloadStage) commit() {
+	for valueBean, result := range s.values {
+		valueBean.Value = result
+		valueBean.resetValue()
+	}
+	for _, bean := range s.recalled {
+		bean.Factory.OutValue.Set(s.results[bean.Value.Addr().Interface()].Elem())
 	}
 }
@@ -1,7 +1,9 @@
 package rady
 
 import (
+	"context"
 	"fmt"
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -109,5 +111,14 @@ func (b *BookController) GetConfReload(ctx Context) error {
 
 func TestCreateApplication(t *testing.T) {
 	CreateTest(new(App)).AddTest(new(AppTest)).AddTests(new(AppTest)).Test(t)
-	go CreateApplication(new(App)).Run()
+
+	app, err := CreateApplication(new(App)).Build()
+	assert.Nil(t, err)
+	*app.Addr = "127.0.0.1:0"
+	served := make(chan error, 1)
+	go func() {
+		served <- app.serve()
+	}()
+	assert.Nil(t, app.Shutdown(context.Background()))
+	assert.Nil(t, <-served)
 }
@@ -1,8 +1,8 @@
 package rady
 
 import (
+	"fmt"
 	"github.com/tidwall/gjson"
-	"os"
 	"reflect"
 	"time"
 )
@@ -33,6 +33,7 @@ type (
 		Name     string
 		OutValue reflect.Value
 		InValues []reflect.Value
+		Out      *Bean
 	}
 
 	/*
@@ -41,6 +42,8 @@ type (
 		ValueMap is different types the value converted to
 
 		ParamSlice is the param list contain this value
+
+		Env is the environment variable in `env` tag of the first field loading this value
 	*/
 	ValueBean struct {
 		Value     gjson.Result
@@ -48,59 +51,139 @@ type (
 		MethodSet map[*Method]bool
 		Key       string
 		Default   gjson.Result
+		Env       string
 	}
 
-	// Bean contains the value and tag of a type
+	/*
+		Bean contains the value and tag of a type
+
+		Deps is the beans injected into this bean, or passed to its factory
+
+		Factory is the 'BeanMethod' this bean is returned by, nil for normal bean
+
+		Template is the prototype or request bean in BeanMap this bean is instantiated from, nil for beans in BeanMap
+
+		Scope is the request scope this bean is instantiated in, nil for beans out of request
+
+		Proxies is values injected into Interface injection points instead of this bean, see Expose
+
+		Source is how the bean is loaded, like SourceRoot or SourceFactory
+
+		Namespace is the namespace of module the bean is loaded in, see Module
+	*/
 	Bean struct {
-		Tag   reflect.StructTag
-		Value reflect.Value
+		Name      string
+		Type      reflect.Type
+		Tag       reflect.StructTag
+		Value     reflect.Value
+		Deps      []*Dependency
+		Factory   *Method
+		Template  *Bean
+		Scope     *RequestScope
+		Proxies   []reflect.Value
+		Source    string
+		Namespace string
 	}
 )
 
 func (m *Method) LoadIns(app *Application) {
-	for _, inType := range m.Ins {
+	for i, inType := range m.Ins {
 		if ConfirmSameTypeInMap(app.BeanMap, inType) {
 			if len(app.BeanMap[inType]) > 1 {
-				app.Logger.Critical("There are more than one %s, please named it.", inType)
-				os.Exit(1)
+				app.addError(NewBeanError(AmbiguousBean, inType, "", GetParamPath(m.Name, i), "there are more than one bean of this type, please name it"))
+				continue
 			}
 			for _, bean := range app.BeanMap[inType] {
 				m.InValues = append(m.InValues, bean.Value)
+				m.Out.Deps = append(m.Out.Deps, NewDependency(bean, GetParamPath(m.Name, i), true))
 			}
 		} else {
 			newValue := reflect.New(inType.Elem()).Elem()
+			namespace := app.namespace
+			app.namespace = m.Out.Namespace
 			app.load(inType, newValue, GetTagFromName(""))
+			app.namespace = namespace
 			m.InValues = append(m.InValues, newValue)
+			m.Out.Deps = append(m.Out.Deps, NewDependency(app.BeanMap[inType][inType.String()], GetParamPath(m.Name, i), true))
 		}
 	}
 }
 
-func (m *Method) Call(app *Application) {
+// Call call the factory and set OutValue, return error returned by the factory, or when result is nil
+func (m *Method) Call(app *Application) error {
 	params := make([]reflect.Value, 0)
 	for _, value := range m.InValues {
 		params = append(params, value.Addr())
 	}
+	result, err := m.call(params)
+	if err != nil {
+		return err
+	}
+	app.Logger.Debug("Result of %s set %s", m.Name, result.Elem())
+	m.OutValue.Set(result.Elem())
+	return nil
+}
+
+// call call the factory with params, return its result, or error returned by the factory, or when result is nil
+func (m *Method) call(params []reflect.Value) (reflect.Value, error) {
 	result := m.Value.Call(params)
-	if len(result) != 1 {
-		app.Logger.Error("Result of %s is not a Component!!!", m.Name)
-		os.Exit(1)
+	if len(result) == 2 && !result[1].IsNil() {
+		return reflect.Value{}, result[1].Interface().(error)
+	}
+	if result[0].IsNil() {
+		return reflect.Value{}, fmt.Errorf("result of %s is nil", m.Name)
 	}
-	app.Logger.Debug("Result of %s set %s", m.Name, result[0].Elem())
-	m.OutValue.Set(result[0].Elem())
+	return result[0], nil
 }
 
-func (v *ValueBean) Reload(a *Application) {
-	newResult := gjson.Get(a.ConfigFile, v.Key)
-	if newResult != v.Value {
-		if !newResult.Exists() {
-			a.Logger.Info("Key %s doesn't exist, use default value %s", v.Key, v.Default.String())
-			newResult = v.Default
+// Expose return the last one of Proxies assignable to targetType, or Ptr of the bean when there is none
+func (b *Bean) Expose(targetType reflect.Type) reflect.Value {
+	for i := len(b.Proxies) - 1; i >= 0; i-- {
+		if b.Proxies[i].Type().AssignableTo(targetType) {
+			return b.Proxies[i]
 		}
-		v.Value = newResult
-		a.Logger.Debug("Reset Value '%s' to %s", v.Key, v.Value.String())
-		v.resetValue()
-		v.recallFactory(a)
 	}
+	return b.Value.Addr()
+}
+
+// Clone return a copy of the factory whose result is set to out
+func (m *Method) Clone(out *Bean) *Method {
+	return &Method{
+		Value:    m.Value,
+		Ins:      m.Ins,
+		Name:     m.Name,
+		OutValue: out.Value,
+		InValues: append([]reflect.Value{}, m.InValues...),
+		Out:      out,
+	}
+}
+
+// Reload reset the value from config file and mark its factories to recall, return false and do nothing when the value isn't changed
+func (v *ValueBean) Reload(a *Application) bool {
+	newResult := v.lookup(a, a.ConfigFile)
+	if !v.changed(newResult) {
+		return false
+	}
+	v.Value = newResult
+	a.Logger.Debug("Reset Value '%s' to %s", v.Key, v.Value.String())
+	v.resetValue()
+	v.recallFactory(a)
+	return true
+}
+
+// lookup return the value of Key from config or env, Default when it doesn't exist
+func (v *ValueBean) lookup(a *Application, config string) gjson.Result {
+	result := a.lookupValueIn(config, v.Key, v.Env)
+	if !result.Exists() {
+		a.Logger.Info("Key %s doesn't exist, use default value %s", v.Key, v.Default.String())
+		return v.Default
+	}
+	return result
+}
+
+// changed return true when result is different from Value
+func (v *ValueBean) changed(result gjson.Result) bool {
+	return result.Type != v.Value.Type || result.Raw != v.Value.Raw
 }
 
 func (v *ValueBean) resetValue() {
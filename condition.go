@@ -0,0 +1,122 @@
+package rady
+
+import (
+	"reflect"
+	"strings"
+)
+
+/*
+Condition is a tag to register a factory method in configuration only when conditions hold
+
+Usage:
+
+	type CacheConfig struct {
+		Configuration
+		Redis  Condition `method:"GetRedisCache" on-key:"rady.redis.host"`
+		Memory Condition `method:"GetMemoryCache" on-missing:"*Cache"`
+	}
+
+	type Root struct {
+		*CacheConfig
+		*ProdConfig `on-mode:"prod,staging"`
+	}
+
+conditions can also be tagged on configuration fields, see checkCondition
+*/
+type Condition struct {
+}
+
+// ConditionType is the type of Condition
+var ConditionType = reflect.TypeOf(Condition{})
+
+// GetMethodConditions return tags of Condition fields in configType, found by `method`
+func GetMethodConditions(configType reflect.Type) map[string]reflect.StructTag {
+	conditions := make(map[string]reflect.StructTag)
+	for i := 0; i < configType.NumField(); i++ {
+		field := configType.Field(i)
+		if field.Type == ConditionType {
+			if method := strings.Trim(field.Tag.Get("method"), " "); method != "" {
+				conditions[method] = field.Tag
+			}
+		}
+	}
+	return conditions
+}
+
+// MatchTypeName return true when name is string of fieldType like `*rady.Cache`, or the same without package like `*Cache`
+func MatchTypeName(fieldType reflect.Type, name string) bool {
+	if fieldType.String() == name {
+		return true
+	}
+	prefix := ""
+	for fieldType.Kind() == reflect.Ptr {
+		prefix += "*"
+		fieldType = fieldType.Elem()
+	}
+	return prefix+fieldType.Name() == name
+}
+
+/*
+checkCondition return true when all conditions in tag hold
+
+	on-mode: any of GetModes() is one of the modes, see MatchMode
+
+	on-key: the key exists in config file, or is overridden by env named by GetEnvName(key)
+
+`on-missing` is checked by checkMissing, after all unconditional beans are loaded
+*/
+func (a *Application) checkCondition(tag reflect.StructTag) bool {
+	if modes, ok := tag.Lookup("on-mode"); ok && !MatchMode(modes) {
+		return false
+	}
+	if key := strings.Trim(tag.Get("on-key"), " "); key != "" && !a.lookupValue(key, "").Exists() {
+		return false
+	}
+	return true
+}
+
+// checkMissing return true when there is no bean of the type in `on-missing`
+func (a *Application) checkMissing(tag reflect.StructTag) bool {
+	typeName := strings.Trim(tag.Get("on-missing"), " ")
+	for fieldType, beans := range a.BeanMap {
+		if len(beans) > 0 && MatchTypeName(fieldType, typeName) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+loadConditionally call load when conditions in tag hold, name is used in log
+
+load is delayed until all unconditional beans are loaded when tag has `on-missing`, see loadOnMissing
+*/
+func (a *Application) loadConditionally(tag reflect.StructTag, name string, load func()) {
+	if !a.checkCondition(tag) {
+		a.Logger.Debug("Skip %s: conditions don't hold", name)
+		return
+	}
+	if tag.Get("on-missing") == "" {
+		load()
+		return
+	}
+	namespace := a.namespace
+	a.onMissing = append(a.onMissing, func() {
+		if !a.checkMissing(tag) {
+			a.Logger.Debug("Skip %s: %s exists", name, tag.Get("on-missing"))
+			return
+		}
+		parent := a.namespace
+		a.namespace = namespace
+		load()
+		a.namespace = parent
+	})
+}
+
+// loadOnMissing call loaders delayed by loadConditionally in order, a loader can see beans loaded by former ones
+func (a *Application) loadOnMissing() {
+	for i := 0; i < len(a.onMissing); i++ {
+		a.onMissing[i]()
+	}
+	a.onMissing = nil
+}
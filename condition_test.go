@@ -0,0 +1,125 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type (
+	ConditionRoot struct {
+		*ConditionConfig
+		*ProdConfig     `on-mode:"prod,staging"`
+		*NoKeyConfig    `on-key:"rady.nothing"`
+		*FallbackConfig `on-missing:"*ConditionService"`
+	}
+
+	ConditionConfig struct {
+		Configuration
+		Redis    Condition `method:"GetRedisCache" on-key:"rady.redis.host"`
+		Memory   Condition `method:"GetMemoryCache" on-missing:"*ConditionCache"`
+		Prod     Condition `method:"GetProdStore" on-mode:"prod"`
+		Fallback Condition `method:"GetFallbackStore" on-missing:"*rady.ConditionStore"`
+	}
+
+	ProdConfig struct {
+		Configuration
+	}
+
+	NoKeyConfig struct {
+		Configuration
+	}
+
+	FallbackConfig struct {
+		Configuration
+	}
+
+	ConditionCache struct {
+		Component
+		Name string
+	}
+
+	ConditionStore struct {
+		Repository
+		Name string
+	}
+
+	ConditionService struct {
+		Service
+		Name string
+	}
+
+	ConditionTest struct {
+		Testing
+		Cache   *ConditionCache
+		Store   *ConditionStore
+		Service *ConditionService
+	}
+)
+
+func (c *ConditionConfig) GetRedisCache() *ConditionCache {
+	return &ConditionCache{Name: "redis"}
+}
+
+func (c *ConditionConfig) GetMemoryCache() *ConditionCache {
+	return &ConditionCache{Name: "memory"}
+}
+
+func (c *ConditionConfig) GetProdStore() *ConditionStore {
+	return &ConditionStore{Name: "prod"}
+}
+
+func (c *ConditionConfig) GetFallbackStore() *ConditionStore {
+	return &ConditionStore{Name: "fallback"}
+}
+
+func (c *ProdConfig) GetProdService() *ConditionService {
+	return &ConditionService{Name: "prod"}
+}
+
+func (c *NoKeyConfig) GetNoKeyService() *ConditionService {
+	return &ConditionService{Name: "no key"}
+}
+
+func (c *FallbackConfig) GetFallbackService() *ConditionService {
+	return &ConditionService{Name: "fallback"}
+}
+
+func (c *ConditionTest) TestCondition(t *testing.T) {
+	assert.Equal(t, "redis", c.Cache.Name)
+	assert.Equal(t, "fallback", c.Store.Name)
+	assert.Equal(t, "fallback", c.Service.Name)
+}
+
+func TestCondition(t *testing.T) {
+	CreateTest(new(ConditionRoot)).AddTest(new(ConditionTest)).Test(t)
+}
+
+func TestConditionOnMode(t *testing.T) {
+	os.Setenv(ModeEnv, "staging")
+	defer ResetEnv(ModeEnv)
+	app, err := CreateApplication(new(ConditionRoot)).Build()
+	assert.Nil(t, err)
+	assert.Contains(t, app.BeanMap[reflect.TypeOf(new(ConditionService))], "GetProdService")
+	assert.NotContains(t, app.BeanMap[reflect.TypeOf(new(ConditionService))], "GetFallbackService")
+	assert.NotContains(t, app.BeanMap[reflect.TypeOf(new(ConditionCache))], "GetMemoryCache")
+	assert.Contains(t, app.BeanMap[reflect.TypeOf(new(ConditionStore))], "GetFallbackStore")
+}
+
+func TestConditionOnModes(t *testing.T) {
+	os.Setenv(ModeEnv, "eu,prod")
+	defer ResetEnv(ModeEnv)
+	app, err := CreateApplication(new(ConditionRoot)).Build()
+	assert.Nil(t, err)
+	assert.Contains(t, app.BeanMap[reflect.TypeOf(new(ConditionService))], "GetProdService")
+	assert.Contains(t, app.BeanMap[reflect.TypeOf(new(ConditionStore))], "GetProdStore")
+	assert.NotContains(t, app.BeanMap[reflect.TypeOf(new(ConditionStore))], "GetFallbackStore")
+}
+
+func TestMatchTypeName(t *testing.T) {
+	cacheType := reflect.TypeOf(new(ConditionCache))
+	assert.True(t, MatchTypeName(cacheType, "*rady.ConditionCache"))
+	assert.True(t, MatchTypeName(cacheType, "*ConditionCache"))
+	assert.False(t, MatchTypeName(cacheType, "ConditionCache"))
+}
@@ -1,5 +1,10 @@
 package rady
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 const (
 	// DefaultPath is the path of config file for default
 	DefaultPath = "./resources/application.conf"
@@ -11,11 +16,22 @@ const (
 
 	// JSON is the suffix of json file
 	JSON = "json"
+
+	// TOML is the suffix of toml file
+	TOML = "toml"
+
+	// PROPERTIES is the suffix of java properties file
+	PROPERTIES = "properties"
+
+	// DOTENV is the suffix of dotenv file
+	DOTENV = "env"
 )
 
 /*
 CONF is a tag of Boot to define the path and type of config file
 
+type is one of types in ConfigDecoders, or it is decided by suffix of path, see GetConfigType
+
 Usage:
 
 	type Root struct {
@@ -28,3 +44,41 @@ Usage:
 */
 type CONF struct {
 }
+
+// MergeJSON deep merge overlay on base, objects are merged recursively, and other values, arrays included, are replaced
+func MergeJSON(base, overlay string) (string, error) {
+	baseValue, err := decodeJSON(base)
+	if err != nil {
+		return "", err
+	}
+	overlayValue, err := decodeJSON(overlay)
+	if err != nil {
+		return "", err
+	}
+	merged, err := json.Marshal(mergeValue(baseValue, overlayValue))
+	return string(merged), err
+}
+
+// decodeJSON decode content, numbers are kept as json.Number so they are not rounded
+func decodeJSON(content string) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewBufferString(content))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+func mergeValue(base, overlay interface{}) interface{} {
+	baseMap, baseOk := base.(map[string]interface{})
+	overlayMap, overlayOk := overlay.(map[string]interface{})
+	if !baseOk || !overlayOk {
+		return overlay
+	}
+	for key, value := range overlayMap {
+		if baseValue, ok := baseMap[key]; ok {
+			value = mergeValue(baseValue, value)
+		}
+		baseMap[key] = value
+	}
+	return baseMap
+}
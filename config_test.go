@@ -2,6 +2,9 @@ package rady
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
+	"os"
 	"testing"
 )
 
@@ -20,12 +23,12 @@ type ConfigTestSuffixJSON struct {
 	CONF `path:"./resources/application.json"`
 }
 
-type ConfigTestTypeTomlSuffixYAML struct {
-	CONF `path:"./resources/application.yaml" type:"toml"`
+type ConfigTestTypeIniSuffixYAML struct {
+	CONF `path:"./resources/application.yaml" type:"ini"`
 }
 
-type ConfigTestTypeTomlSuffixJSON struct {
-	CONF `path:"./resources/application.json" type:"toml"`
+type ConfigTestTypeIniSuffixJSON struct {
+	CONF `path:"./resources/application.json" type:"ini"`
 }
 
 var (
@@ -62,8 +65,8 @@ func TestConfigFilePath(t *testing.T) {
 		new(ConfigTestTypeJSON),
 		new(ConfigTestSuffixYAML),
 		new(ConfigTestSuffixJSON),
-		new(ConfigTestTypeTomlSuffixYAML),
-		new(ConfigTestTypeTomlSuffixJSON),
+		new(ConfigTestTypeIniSuffixYAML),
+		new(ConfigTestTypeIniSuffixJSON),
 	}
 
 	results := [][]string{
@@ -80,3 +83,59 @@ func TestConfigFilePath(t *testing.T) {
 		assert.Equal(t, results[i][1], Type)
 	}
 }
+
+func TestMergeJSON(t *testing.T) {
+	merged, err := MergeJSON(
+		`{"rady": {"redis": {"host": "127.0.0.1", "port": 6937}, "ports": [80, 443], "id": 9007199254740993}}`,
+		`{"rady": {"redis": {"host": "redis"}, "ports": [8080], "mode": "prod"}}`,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "redis", gjson.Get(merged, "rady.redis.host").String())
+	assert.Equal(t, int64(6937), gjson.Get(merged, "rady.redis.port").Int())
+	assert.Equal(t, `[8080]`, gjson.Get(merged, "rady.ports").Raw)
+	assert.Equal(t, "prod", gjson.Get(merged, "rady.mode").String())
+	assert.Equal(t, "9007199254740993", gjson.Get(merged, "rady.id").Raw)
+
+	_, err = MergeJSON(`{"rady": {}}`, `{"rady": `)
+	assert.NotNil(t, err)
+}
+
+type ConfigTestLayered struct {
+	CONF `path:"./resources/layered.json"`
+}
+
+func TestConfigLayering(t *testing.T) {
+	files := map[string]string{
+		"./resources/layered.json":      `{"rady": {"redis": {"host": "127.0.0.1", "port": 6937}, "server": {"ports": [80, 443]}}}`,
+		"./resources/prod.layered.json": `{"rady": {"redis": {"host": "redis"}, "server": {"ports": [8080]}}}`,
+		"./resources/eu.layered.json":   `{"rady": {"redis": {"port": 6379}}}`,
+	}
+	for path, content := range files {
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+		defer os.Remove(path)
+	}
+
+	os.Setenv(ModeEnv, "prod, eu, us")
+	defer ResetEnv(ModeEnv)
+	app := CreateApplication(new(ConfigTestLayered))
+	paths, _ := app.GetConfigPathsAndType()
+	assert.Equal(t, []string{"./resources/layered.json", "./resources/prod.layered.json", "./resources/eu.layered.json", "./resources/us.layered.json"}, paths)
+	assert.Equal(t, "redis", gjson.Get(app.ConfigFile, "rady.redis.host").String())
+	assert.Equal(t, int64(6379), gjson.Get(app.ConfigFile, "rady.redis.port").Int())
+	assert.Equal(t, `[8080]`, gjson.Get(app.ConfigFile, "rady.server.ports").Raw)
+
+	os.Setenv(ModeEnv, "eu")
+	app = CreateApplication(new(ConfigTestLayered))
+	assert.Equal(t, "127.0.0.1", gjson.Get(app.ConfigFile, "rady.redis.host").String())
+	assert.Equal(t, int64(6379), gjson.Get(app.ConfigFile, "rady.redis.port").Int())
+	assert.Equal(t, `[80,443]`, gjson.Get(app.ConfigFile, "rady.server.ports").Raw)
+
+	assert.Equal(t, "./resources/eu.layered.json", app.GetActiveConfigPath())
+	assert.Nil(t, app.WriteConfigFile(`{"rady": {"redis": {"port": 6380}}}`))
+	assert.Nil(t, app.ReloadValues())
+	assert.Equal(t, int64(6380), gjson.Get(app.ConfigFile, "rady.redis.port").Int())
+	assert.Equal(t, "127.0.0.1", gjson.Get(app.ConfigFile, "rady.redis.host").String())
+
+	ResetEnv(ModeEnv)
+	assert.Equal(t, "./resources/layered.json", app.GetActiveConfigPath())
+}
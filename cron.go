@@ -0,0 +1,29 @@
+package rady
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+)
+
+/*
+CronParser parse cron expressions with fields `second minute hour day-of-month month day-of-week`
+
+the second field can be omitted, descriptors like `@hourly` and `@every 1h30m` are supported,
+see https://pkg.go.dev/github.com/robfig/cron/v3 for the syntax
+
+	month and day-of-week accept names like `JAN` and `MON`, day-of-week is in [0, 6] and `7` is not Sunday
+
+	`?` is the same as `*` in day-of-month and day-of-week
+
+	day is matched when both day-of-month and day-of-week match, or either of them when neither is `*` or `?`
+*/
+var CronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseCron parse expression by CronParser, Next of the schedule return zero time when there is no match in 5 years
+func ParseCron(expression string) (cron.Schedule, error) {
+	schedule, err := CronParser.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("cron '%s' invalid: %s", expression, err)
+	}
+	return schedule, nil
+}
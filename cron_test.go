@@ -0,0 +1,35 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	start := time.Date(2018, 1, 30, 10, 3, 20, 0, time.UTC)
+	for expression, next := range map[string]time.Time{
+		"0 0/5 * * * *":            time.Date(2018, 1, 30, 10, 5, 0, 0, time.UTC),
+		"*/10 * * * * *":           time.Date(2018, 1, 30, 10, 3, 30, 0, time.UTC),
+		"30 2 * * *":               time.Date(2018, 1, 31, 2, 30, 0, 0, time.UTC),
+		"0 0 9-17 * * 1-5":         time.Date(2018, 1, 30, 11, 0, 0, 0, time.UTC),
+		"0 0 0 1,15 * *":           time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 0 29 2 *":             time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 0 13 * 5":             time.Date(2018, 2, 2, 0, 0, 0, 0, time.UTC),
+		"@hourly":                  time.Date(2018, 1, 30, 11, 0, 0, 0, time.UTC),
+		"@weekly":                  time.Date(2018, 2, 4, 0, 0, 0, 0, time.UTC),
+		"0 0 0 31 4 *":             {},
+		"0 30 9 * jan-mar MON-FRI": time.Date(2018, 1, 31, 9, 30, 0, 0, time.UTC),
+		"0 0 0 ? * SUN":            time.Date(2018, 2, 4, 0, 0, 0, 0, time.UTC),
+		"@every 90s":               time.Date(2018, 1, 30, 10, 4, 50, 0, time.UTC),
+	} {
+		schedule, err := ParseCron(expression)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, next, schedule.Next(start), expression)
+	}
+
+	for _, expression := range []string{"* * *", "60 * * * * *", "* * * * 0 *", "*/0 * * * * *", "a * * * * *", "5-1 * * * * *", "0 0 0 * * 7", "0 0 0 * FOO *"} {
+		_, err := ParseCron(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
@@ -0,0 +1,259 @@
+package rady
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ghodss/yaml"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ConfigDecoder decode content of config file into json
+type ConfigDecoder func(content []byte) ([]byte, error)
+
+var (
+	// ConfigDecoders is map to find ConfigDecoder by type of config file, see RegisterConfigDecoder
+	ConfigDecoders = map[string]ConfigDecoder{
+		JSON:       DecodeJSON,
+		YAML:       yaml.YAMLToJSON,
+		TOML:       DecodeTOML,
+		PROPERTIES: DecodeProperties,
+		DOTENV:     DecodeDotenv,
+	}
+
+	// ConfigSuffixes is map to find type of config file by suffix
+	ConfigSuffixes = map[string]string{
+		JSON:       JSON,
+		YAML:       YAML,
+		"yml":      YAML,
+		TOML:       TOML,
+		PROPERTIES: PROPERTIES,
+		DOTENV:     DOTENV,
+	}
+)
+
+/*
+RegisterConfigDecoder register decoder of fileType, files with suffixes are decoded by it when there is no `type` in CONF
+
+Usage:
+
+	func init() {
+		RegisterConfigDecoder("ini", DecodeINI, "ini", "cfg")
+	}
+*/
+func RegisterConfigDecoder(fileType string, decoder ConfigDecoder, suffixes ...string) {
+	ConfigDecoders[fileType] = decoder
+	for _, suffix := range suffixes {
+		ConfigSuffixes[strings.TrimPrefix(suffix, ".")] = fileType
+	}
+}
+
+// GetConfigType return type of config file by suffix of path, false when the suffix is unknown
+func GetConfigType(path string) (string, bool) {
+	fileType, ok := ConfigSuffixes[strings.TrimPrefix(filepath.Ext(path), ".")]
+	return fileType, ok
+}
+
+// DecodeJSON return content as it is
+func DecodeJSON(content []byte) ([]byte, error) {
+	return content, nil
+}
+
+/*
+DecodeProperties decode java properties file, dotted keys are mapped to nested objects and values are strings
+
+	rady.server.addr = :8081
+	rady.server.shutdown-timeout: 10s
+
+lines start with '#' or '!' are comments, and a line ends with '\' is continued on the next line
+
+the last value of a key set twice wins, but a key cannot have both a value and children,
+`a = 1` with `a.b = 2` is an error in either order, see setNested
+*/
+func DecodeProperties(content []byte) ([]byte, error) {
+	root := make(map[string]interface{})
+	lines := strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		number, line := i+1, strings.TrimLeftFunc(lines[i], unicode.IsSpace)
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for endsWithBackslash(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeftFunc(lines[i], unicode.IsSpace)
+		}
+		key, value := splitProperty(line)
+		key, err := unescapeProperty(key)
+		if err == nil {
+			value, err = unescapeProperty(value)
+		}
+		if err == nil {
+			err = setNested(root, key, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", number, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// endsWithBackslash return true when line ends with an odd number of '\'
+func endsWithBackslash(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// splitProperty split line into key and value by the first unescaped '=', ':' or whitespace
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			key, rest := line[:i], strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = rest[1:]
+			}
+			return key, strings.TrimLeft(rest, " \t\f")
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty resolve escapes in key or value of properties file
+func unescapeProperty(text string) (string, error) {
+	if !strings.Contains(text, "\\") {
+		return text, nil
+	}
+	var result strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			result.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 't':
+			result.WriteByte('\t')
+		case 'n':
+			result.WriteByte('\n')
+		case 'r':
+			result.WriteByte('\r')
+		case 'f':
+			result.WriteByte('\f')
+		case 'u':
+			if i+5 > len(text) {
+				return "", fmt.Errorf("invalid unicode escape in '%s'", text)
+			}
+			code, err := strconv.ParseUint(text[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in '%s'", text)
+			}
+			result.WriteRune(rune(code))
+			i += 4
+		default:
+			result.WriteByte(text[i])
+		}
+	}
+	return result.String(), nil
+}
+
+/*
+DecodeDotenv decode dotenv file, keys are lowercased and '_' is replaced by '.', so `RADY_SERVER_ADDR` is `rady.server.addr`
+
+	# comment
+	export RADY_SERVER_ADDR=:8081
+	RADY_DB_URL="postgres://localhost/app" # comment
+	RADY_DB_PASSWORD='p@ss#word'
+
+values in double quotes support escapes \n, \t, \" and \\, values in single quotes are literal
+
+'-' in keys like `rady.server.shutdown-timeout` cannot be written in the form of env,
+write the key in lowercase with dots instead, keys without '_' are kept as they are:
+
+	rady.server.shutdown-timeout=10s
+*/
+func DecodeDotenv(content []byte) ([]byte, error) {
+	root := make(map[string]interface{})
+	for i, line := range strings.Split(strings.Replace(string(content), "\r\n", "\n", -1), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		index := strings.IndexByte(line, '=')
+		if index <= 0 {
+			return nil, fmt.Errorf("line %d: '%s' should be like KEY=value", i+1, line)
+		}
+		key := strings.Replace(strings.ToLower(strings.TrimSpace(line[:index])), "_", ".", -1)
+		value, err := unquoteDotenv(strings.TrimSpace(line[index+1:]))
+		if err == nil {
+			err = setNested(root, key, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// unquoteDotenv return value in quotes, or value before an inline comment when it is not quoted
+func unquoteDotenv(value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+	switch quote := value[0]; quote {
+	case '\'', '"':
+		end := 1
+		for ; end < len(value) && value[end] != quote; end++ {
+			if quote == '"' && value[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(value) {
+			return "", fmt.Errorf("quote of %s is not closed", value)
+		}
+		if quote == '\'' {
+			return value[1:end], nil
+		}
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value[1:end]), nil
+	}
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value, nil
+}
+
+/*
+setNested set value of dotted key in root, objects in the path are created when they don't exist, and the last value of a key wins
+
+json cannot hold both a value and children of a key, so an error is returned when key is under a value set before,
+like `a.b` after `a`, or key is an object set before, like `a` after `a.b`
+*/
+func setNested(root map[string]interface{}, key string, value interface{}) error {
+	parts := strings.Split(key, ".")
+	node := root
+	for i, part := range parts[:len(parts)-1] {
+		child, ok := node[part]
+		if !ok {
+			child = make(map[string]interface{})
+			node[part] = child
+		}
+		childMap, ok := child.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("key %s is not an object", strings.Join(parts[:i+1], "."))
+		}
+		node = childMap
+	}
+	if _, ok := node[parts[len(parts)-1]].(map[string]interface{}); ok {
+		return fmt.Errorf("key %s is an object", key)
+	}
+	node[parts[len(parts)-1]] = value
+	return nil
+}
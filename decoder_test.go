@@ -0,0 +1,149 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeProperties(t *testing.T) {
+	data, err := DecodeProperties([]byte(`# rady config
+! comment
+rady.server.addr = :8081
+rady.server.shutdown-timeout: 10s
+rady.redis.host   127.0.0.1
+rady.redis.port=6937
+rady.mysql.dsn = root@localhost\
+    /app
+rady.name = rady\tinject
+rady.key\=with\:colon = value
+rady.redis.port=6379
+`))
+	assert.Nil(t, err)
+	config := string(data)
+	assert.Equal(t, ":8081", gjson.Get(config, "rady.server.addr").String())
+	assert.Equal(t, "10s", gjson.Get(config, "rady.server.shutdown-timeout").String())
+	assert.Equal(t, "127.0.0.1", gjson.Get(config, "rady.redis.host").String())
+	assert.Equal(t, int64(6379), gjson.Get(config, "rady.redis.port").Int())
+	assert.Equal(t, "root@localhost/app", gjson.Get(config, "rady.mysql.dsn").String())
+	assert.Equal(t, "rady\tinject", gjson.Get(config, "rady.name").String())
+	assert.Equal(t, "value", gjson.Get(config, "rady.key=with:colon").String())
+
+	_, err = DecodeProperties([]byte("rady.redis = redis\nrady.redis.host = 127.0.0.1"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2: key rady.redis is not an object")
+	_, err = DecodeProperties([]byte("rady.redis.host = 127.0.0.1\nrady.redis = redis"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2: key rady.redis is an object")
+}
+
+func TestDecodeDotenv(t *testing.T) {
+	data, err := DecodeDotenv([]byte(`# rady config
+export RADY_SERVER_ADDR=:8081
+RADY_REDIS_HOST = 127.0.0.1 # comment
+RADY_DB_URL="postgres://localhost/app\n"
+RADY_DB_PASSWORD='p@ss#word'
+rady.name=rady
+rady.server.shutdown-timeout=10s
+RADY_SERVER_SHUTDOWN_TIMEOUT=20s
+`))
+	assert.Nil(t, err)
+	config := string(data)
+	assert.Equal(t, ":8081", gjson.Get(config, "rady.server.addr").String())
+	assert.Equal(t, "127.0.0.1", gjson.Get(config, "rady.redis.host").String())
+	assert.Equal(t, "postgres://localhost/app\n", gjson.Get(config, "rady.db.url").String())
+	assert.Equal(t, "p@ss#word", gjson.Get(config, "rady.db.password").String())
+	assert.Equal(t, "rady", gjson.Get(config, "rady.name").String())
+	assert.Equal(t, "10s", gjson.Get(config, "rady.server.shutdown-timeout").String())
+	assert.Equal(t, "20s", gjson.Get(config, "rady.server.shutdown.timeout").String())
+
+	_, err = DecodeDotenv([]byte("RADY_NAME"))
+	assert.NotNil(t, err)
+	_, err = DecodeDotenv([]byte(`RADY_NAME="rady`))
+	assert.NotNil(t, err)
+}
+
+func TestGetConfigType(t *testing.T) {
+	testSets := map[string]string{
+		"./resources/application.json":       JSON,
+		"./resources/application.yml":        YAML,
+		"./resources/application.toml":       TOML,
+		"./resources/application.properties": PROPERTIES,
+		"./.env":                             DOTENV,
+	}
+	for path, fileType := range testSets {
+		realType, ok := GetConfigType(path)
+		assert.True(t, ok)
+		assert.Equal(t, fileType, realType)
+	}
+	_, ok := GetConfigType(DefaultPath)
+	assert.False(t, ok)
+}
+
+func TestRegisterConfigDecoder(t *testing.T) {
+	RegisterConfigDecoder("upper", func(content []byte) ([]byte, error) {
+		return []byte(strings.ToUpper(string(content))), nil
+	}, ".up")
+	defer func() {
+		delete(ConfigDecoders, "upper")
+		delete(ConfigSuffixes, "up")
+	}()
+	assert.Nil(t, ioutil.WriteFile("./resources/application.up", []byte(`{"rady": "inject"}`), 0644))
+	defer os.Remove("./resources/application.up")
+
+	config, err := GetJSONFromAnyFile("./resources/application.up", "")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"RADY": "INJECT"}`, config)
+}
+
+type (
+	TOMLRoot struct {
+		CONF `path:"./resources/decoder.toml"`
+		*DecoderController
+	}
+
+	PropertiesRoot struct {
+		CONF `path:"./resources/decoder.properties"`
+		*DecoderController
+	}
+
+	DotenvRoot struct {
+		CONF `path:"./resources/decoder.conf" type:"env"`
+		*DecoderController
+	}
+
+	DecoderController struct {
+		Controller
+		Host *string `value:"rady.redis.host"`
+		Port *int64  `value:"rady.redis.port"`
+	}
+)
+
+func TestConfigDecoders(t *testing.T) {
+	files := map[string]string{
+		"./resources/decoder.toml":       "[rady.redis]\nhost = \"toml\"\nport = 1",
+		"./resources/decoder.properties": "rady.redis.host = properties\nrady.redis.port = 2",
+		"./resources/decoder.conf":       "RADY_REDIS_HOST=env\nRADY_REDIS_PORT=3",
+	}
+	for path, content := range files {
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+		defer os.Remove(path)
+	}
+
+	roots := []interface{}{new(TOMLRoot), new(PropertiesRoot), new(DotenvRoot)}
+	results := []struct {
+		host string
+		port int64
+	}{{"toml", 1}, {"properties", 2}, {"env", 3}}
+	for i, root := range roots {
+		app, err := CreateApplication(root).Build()
+		assert.Nil(t, err)
+		ctrl := app.BeanMap[reflect.TypeOf(new(DecoderController))]["*rady.DecoderController"].Value.Addr().Interface().(*DecoderController)
+		assert.Equal(t, results[i].host, *ctrl.Host)
+		assert.Equal(t, results[i].port, *ctrl.Port)
+	}
+}
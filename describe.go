@@ -0,0 +1,170 @@
+package rady
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// SourceRoot is the source of beans loaded from fields of Root, or tests
+	SourceRoot = "root"
+
+	// SourceConfiguration is the source of configurations, and beans loaded from fields of configurations
+	SourceConfiguration = "configuration"
+
+	// SourceFactory is the source of beans returned by factories
+	SourceFactory = "factory"
+
+	// SourceRegistered is the source of beans loaded by Register
+	SourceRegistered = "registered"
+
+	// SourceRecursive is the source of beans loaded as fields of other beans
+	SourceRecursive = "recursive"
+
+	// SourceApplication is the source of Application, Logger, EventPublisher and ConfigWatcher
+	SourceApplication = "application"
+)
+
+type (
+	// Description is a structured model of beans in container, sorted by type and name
+	Description struct {
+		Beans []*BeanDescription `json:"beans"`
+	}
+
+	/*
+		BeanDescription describes a bean
+
+		Factory is name of the factory returns the bean, empty for bean not returned by factory
+
+		Values is keys of config file bound to value fields of the bean, or fields of its factory params
+	*/
+	BeanDescription struct {
+		Type         string                   `json:"type"`
+		Name         string                   `json:"name"`
+		Source       string                   `json:"source"`
+		Scope        string                   `json:"scope"`
+		Factory      string                   `json:"factory,omitempty"`
+		Dependencies []*DependencyDescription `json:"dependencies"`
+		Values       []string                 `json:"values"`
+	}
+
+	// DependencyDescription describes a bean injected into the field or factory param with Path
+	DependencyDescription struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+		Path string `json:"path"`
+	}
+)
+
+/*
+Describe return a structured model of all beans in BeanMap, with their dependencies and bound values
+
+instances of prototype and request beans are described as the beans they are instantiated from
+
+Usage:
+
+	app, err := CreateApplication(new(Root)).Build()
+	data, err := app.Describe().JSON()
+	ioutil.WriteFile("beans.dot", []byte(app.Describe().DOT()), 0644)
+*/
+func (a *Application) Describe() *Description {
+	a.lazyLock.Lock()
+	defer a.lazyLock.Unlock()
+	description := &Description{Beans: make([]*BeanDescription, 0)}
+	for _, bean := range a.sortedBeans() {
+		beanDescription := &BeanDescription{
+			Type:         bean.Type.String(),
+			Name:         bean.Name,
+			Source:       bean.Source,
+			Scope:        GetScope(bean),
+			Dependencies: make([]*DependencyDescription, 0, len(bean.Deps)),
+			Values:       a.boundValues(bean),
+		}
+		if bean.Factory != nil {
+			beanDescription.Factory = bean.Factory.Name
+		}
+		for _, dep := range bean.Deps {
+			child := dep.Bean
+			if child.Template != nil {
+				child = child.Template
+			}
+			beanDescription.Dependencies = append(beanDescription.Dependencies, &DependencyDescription{
+				Type: child.Type.String(),
+				Name: child.Name,
+				Path: dep.Path,
+			})
+		}
+		description.Beans = append(description.Beans, beanDescription)
+	}
+	return description
+}
+
+// boundValues return sorted keys of value fields of the bean, or of ValueBeans bound with its factory
+func (a *Application) boundValues(bean *Bean) []string {
+	values := make([]string, 0)
+	if bean.Factory != nil {
+		for key, valueBean := range a.ValueBeanMap {
+			if valueBean.MethodSet[bean.Factory] {
+				values = append(values, key)
+			}
+		}
+	} else if CheckPtrOfStruct(bean.Type) {
+		for i := 0; i < bean.Type.Elem().NumField(); i++ {
+			if field := bean.Type.Elem().Field(i); CheckValues(field) {
+				if key := strings.Trim(field.Tag.Get("value"), " "); key != "" {
+					values = append(values, ResolveKey(bean.Namespace, key))
+				}
+			}
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// JSON return the description encoded as indented JSON
+func (d *Description) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// DOT return the description as a Graphviz digraph, every bean is a node and every dependency is an edge labeled by its path
+func (d *Description) DOT() string {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("digraph rady {\n")
+	buffer.WriteString("\tnode [shape=box];\n")
+	for _, bean := range d.Beans {
+		fmt.Fprintf(buffer, "\t%q [label=%q];\n", bean.ID(), fmt.Sprintf("%s\n%s\n(%s)", bean.Type, bean.Name, bean.Source))
+	}
+	for _, bean := range d.Beans {
+		for _, dep := range bean.Dependencies {
+			fmt.Fprintf(buffer, "\t%q -> %q [label=%q];\n", bean.ID(), dep.ID(), dep.Path)
+		}
+	}
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+// Table return the description as a table, a row for every bean
+func (d *Description) Table() string {
+	buffer := new(bytes.Buffer)
+	writer := tabwriter.NewWriter(buffer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "TYPE\tNAME\tSOURCE\tSCOPE\tDEPENDENCIES\tVALUES")
+	for _, bean := range d.Beans {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\n", bean.Type, bean.Name, bean.Source, bean.Scope, len(bean.Dependencies), strings.Join(bean.Values, ","))
+	}
+	writer.Flush()
+	return buffer.String()
+}
+
+// ID return id of the bean like `[*rady.A][a]`
+func (b *BeanDescription) ID() string {
+	return fmt.Sprintf("[%s][%s]", b.Type, b.Name)
+}
+
+// ID return id of the bean depended like `[*rady.A][a]`
+func (d *DependencyDescription) ID() string {
+	return fmt.Sprintf("[%s][%s]", d.Type, d.Name)
+}
@@ -0,0 +1,82 @@
+package rady
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type (
+	DescribeRoot struct {
+		*DescribeConfig
+		*DescribeController
+	}
+
+	DescribeConfig struct {
+		Configuration
+	}
+
+	DescribeRedis struct {
+		Port *int64 `value:"rady.redis.port"`
+	}
+
+	DescribeClient struct {
+		Component
+	}
+
+	DescribeRepository struct {
+		Repository
+		Host *string `value:"rady.mysql.host"`
+	}
+
+	DescribeController struct {
+		Controller
+		Client *DescribeClient
+		Repo   *DescribeRepository
+	}
+)
+
+func (c *DescribeConfig) GetDescribeClient(redis *DescribeRedis) *DescribeClient {
+	return new(DescribeClient)
+}
+
+func TestDescribe(t *testing.T) {
+	app, err := CreateApplication(new(DescribeRoot)).Build()
+	assert.Nil(t, err)
+	beans := make(map[string]*BeanDescription)
+	for _, bean := range app.Describe().Beans {
+		beans[bean.Type] = bean
+	}
+
+	ctrl := beans["*rady.DescribeController"]
+	assert.Equal(t, SourceRoot, ctrl.Source)
+	assert.Equal(t, Singleton, ctrl.Scope)
+	assert.Len(t, ctrl.Dependencies, 2)
+	assert.Equal(t, "*rady.DescribeController.Client", ctrl.Dependencies[0].Path)
+	assert.Equal(t, "GetDescribeClient", ctrl.Dependencies[0].Name)
+
+	client := beans["*rady.DescribeClient"]
+	assert.Equal(t, SourceFactory, client.Source)
+	assert.Equal(t, "GetDescribeClient", client.Factory)
+	assert.Equal(t, []string{"rady.redis.port"}, client.Values)
+
+	assert.Equal(t, SourceConfiguration, beans["*rady.DescribeConfig"].Source)
+	assert.Equal(t, SourceRecursive, beans["*rady.DescribeRepository"].Source)
+	assert.Equal(t, []string{"rady.mysql.host"}, beans["*rady.DescribeRepository"].Values)
+	assert.Equal(t, SourceApplication, beans["*rady.Application"].Source)
+
+	data, err := app.Describe().JSON()
+	assert.Nil(t, err)
+	var decoded Description
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, len(beans), len(decoded.Beans))
+
+	dot := app.Describe().DOT()
+	assert.True(t, strings.HasPrefix(dot, "digraph rady {"))
+	assert.Contains(t, dot, `"[*rady.DescribeController][*rady.DescribeController]" -> "[*rady.DescribeClient][GetDescribeClient]" [label="*rady.DescribeController.Client"];`)
+
+	table := app.Describe().Table()
+	assert.Contains(t, table, "TYPE")
+	assert.Len(t, strings.Split(strings.TrimRight(table, "\n"), "\n"), len(beans)+1)
+}
@@ -1,7 +1,9 @@
 package rady
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/tidwall/gjson"
 	"os"
 	"strings"
 )
@@ -11,22 +13,56 @@ const (
 	AutoRollbackEnv = "RADY_ROLLBACK"
 	TestMod         = "test"
 	AutoRollback    = "true"
+	EnvKeyPrefix    = "rady."
+	AppEnvPrefix    = "RADY__"
 )
 
 func GetModeEnv() string {
 	return os.Getenv(ModeEnv)
 }
 
+// IsTestMode return true when TestMod is one of GetModes()
 func IsTestMode() bool {
-	return GetModeEnv() == TestMod
+	return MatchMode(TestMod)
+}
+
+// MatchMode return true when any of GetModes() is one of modes separated by comma, an empty mode matches unset RADY_MODE
+func MatchMode(modes string) bool {
+	active := GetModes()
+	if len(active) == 0 {
+		active = []string{""}
+	}
+	for _, expected := range strings.Split(modes, ",") {
+		for _, mode := range active {
+			if strings.Trim(expected, " ") == mode {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func ResetEnv(key string) {
 	os.Setenv(key, "")
 }
 
+// GetModes return modes in RADY_MODE separated by comma in order, like `prod` and `eu` in `prod,eu`
+func GetModes() []string {
+	modes := make([]string, 0)
+	for _, mode := range strings.Split(GetModeEnv(), ",") {
+		if mode = strings.Trim(mode, " "); mode != "" {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
 func GetConfigFileByMode(filePath string) string {
-	mode := GetModeEnv()
+	return GetConfigFileOfMode(filePath, GetModeEnv())
+}
+
+// GetConfigFileOfMode return the overlay of config file in the mode, like `./resources/prod.application.conf`
+func GetConfigFileOfMode(filePath, mode string) string {
 	if mode == "" {
 		return filePath
 	}
@@ -37,3 +73,61 @@ func GetConfigFileByMode(filePath string) string {
 func IsAutoRollback() bool {
 	return os.Getenv(AutoRollbackEnv) == AutoRollback
 }
+
+/*
+GetEnvName return name of the environment variable overriding key, '.' and '-' are replaced by '_' and letters are uppercased
+
+	rady.server.addr: RADY_SERVER_ADDR
+
+	redis.host: RADY__REDIS_HOST
+
+keys not under EnvKeyPrefix are prefixed by AppEnvPrefix, so keys like `path` never read PATH,
+and `redis.host` never collides with `rady.redis.host`
+*/
+func GetEnvName(key string) string {
+	name := strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+	if strings.HasPrefix(key, EnvKeyPrefix) {
+		return name
+	}
+	return AppEnvPrefix + name
+}
+
+// GetEnvValue return value of the environment variable parsed by parseValue, false when it is unset or empty
+func GetEnvValue(name string) (gjson.Result, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return gjson.Result{}, false
+	}
+	return parseValue(value), true
+}
+
+// parseValue parse value from env or command line, json arrays and objects are parsed, others are strings
+func parseValue(value string) gjson.Result {
+	if trimmed := strings.Trim(value, " "); (strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) && gjson.Valid(trimmed) {
+		return gjson.Parse(trimmed)
+	}
+	data, _ := json.Marshal(value)
+	return gjson.ParseBytes(data)
+}
+
+// lookupValue return value of the key from env in `env` tag, env named by GetEnvName(key), Overrides or config file, in order
+func (a *Application) lookupValue(key, env string) gjson.Result {
+	return a.lookupValueIn(a.ConfigFile, key, env)
+}
+
+// lookupValueIn is lookupValue reading config instead of ConfigFile
+func (a *Application) lookupValueIn(config, key, env string) gjson.Result {
+	for _, name := range []string{env, GetEnvName(key)} {
+		if name == "" {
+			continue
+		}
+		if value, ok := GetEnvValue(name); ok {
+			a.Logger.Debug("Value '%s' is overridden by env %s", key, name)
+			return value
+		}
+	}
+	if value, ok := a.Overrides[key]; ok {
+		return value
+	}
+	return gjson.Get(config, key)
+}
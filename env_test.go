@@ -3,6 +3,7 @@ package rady
 import (
 	"github.com/stretchr/testify/assert"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -17,6 +18,10 @@ func TestIsTestMode(t *testing.T) {
 	assert.False(t, IsTestMode())
 	os.Setenv(ModeEnv, TestMod)
 	assert.True(t, IsTestMode())
+	os.Setenv(ModeEnv, "eu, test")
+	assert.True(t, IsTestMode())
+	os.Setenv(ModeEnv, "testing")
+	assert.False(t, IsTestMode())
 	ResetEnv(ModeEnv)
 }
 
@@ -48,3 +53,105 @@ func TestIsAutoRollback(t *testing.T) {
 		ResetEnv(AutoRollbackEnv)
 	}
 }
+
+func TestMatchMode(t *testing.T) {
+	assert.True(t, MatchMode(""))
+	assert.False(t, MatchMode("prod"))
+	os.Setenv(ModeEnv, "prod")
+	assert.True(t, MatchMode("dev, prod"))
+	assert.False(t, MatchMode("dev"))
+	os.Setenv(ModeEnv, "prod, eu")
+	assert.True(t, MatchMode("prod"))
+	assert.True(t, MatchMode("staging, eu"))
+	assert.False(t, MatchMode("staging,us"))
+	assert.False(t, MatchMode(""))
+	ResetEnv(ModeEnv)
+}
+
+func TestGetModes(t *testing.T) {
+	assert.Empty(t, GetModes())
+	os.Setenv(ModeEnv, "prod, eu,")
+	assert.Equal(t, []string{"prod", "eu"}, GetModes())
+	ResetEnv(ModeEnv)
+}
+
+func TestGetEnvName(t *testing.T) {
+	assert.Equal(t, "RADY_SERVER_ADDR", GetEnvName("rady.server.addr"))
+	assert.Equal(t, "RADY_STARTUP_REPORT", GetEnvName("rady.startup-report"))
+	assert.Equal(t, "RADY__PATH", GetEnvName("path"))
+	assert.Equal(t, "RADY__HOME_DIR", GetEnvName("home.dir"))
+	assert.Equal(t, "RADY__REDIS_HOST", GetEnvName("redis.host"))
+}
+
+func TestGetEnvValue(t *testing.T) {
+	_, ok := GetEnvValue("RADY_TEST_VALUE")
+	assert.False(t, ok)
+	os.Setenv("RADY_TEST_VALUE", "localhost")
+	value, ok := GetEnvValue("RADY_TEST_VALUE")
+	assert.True(t, ok)
+	assert.Equal(t, "localhost", value.String())
+	os.Setenv("RADY_TEST_VALUE", "6379")
+	value, _ = GetEnvValue("RADY_TEST_VALUE")
+	assert.Equal(t, int64(6379), value.Int())
+	assert.Equal(t, "6379", value.String())
+	os.Setenv("RADY_TEST_VALUE", "[80, 443]")
+	value, _ = GetEnvValue("RADY_TEST_VALUE")
+	assert.Len(t, value.Array(), 2)
+	ResetEnv("RADY_TEST_VALUE")
+}
+
+type (
+	EnvRoot struct {
+		CONF `path:"./resources/application.yaml"`
+		*EnvController
+	}
+
+	EnvController struct {
+		Controller
+		RedisHost *string  `value:"rady.redis.host"`
+		RedisPort *int64   `value:"rady.redis.port"`
+		Password  *string  `value:"rady.mysql.password" env:"DB_PASSWORD" default:"root"`
+		Ports     *[]int64 `value:"rady.server.ports"`
+		HomeDir   *string  `value:"home.dir" default:"/root"`
+		TmpDir    *string  `value:"tmp.dir" env:"TMP_DIR" default:"/tmp"`
+	}
+)
+
+func TestEnvOverride(t *testing.T) {
+	os.Setenv("RADY_REDIS_HOST", "redis")
+	os.Setenv("DB_PASSWORD", "secret")
+	os.Setenv("RADY_SERVER_PORTS", "[8080]")
+	os.Setenv("HOME_DIR", "/home/rady")
+	os.Setenv("RADY__TMP_DIR", "/home/tmp")
+	os.Setenv("TMP_DIR", "/var/tmp")
+	defer func() {
+		ResetEnv("RADY_REDIS_HOST")
+		ResetEnv("DB_PASSWORD")
+		ResetEnv("RADY_SERVER_PORTS")
+		ResetEnv("HOME_DIR")
+		ResetEnv("RADY__TMP_DIR")
+		ResetEnv("TMP_DIR")
+	}()
+
+	app, err := CreateApplication(new(EnvRoot)).Build()
+	assert.Nil(t, err)
+	ctrl := app.BeanMap[reflect.TypeOf(new(EnvController))]["*rady.EnvController"].Value.Addr().Interface().(*EnvController)
+	assert.Equal(t, "redis", *ctrl.RedisHost)
+	assert.Equal(t, int64(6937), *ctrl.RedisPort)
+	assert.Equal(t, "secret", *ctrl.Password)
+	assert.Equal(t, []int64{8080}, *ctrl.Ports)
+	assert.Equal(t, "/root", *ctrl.HomeDir)
+	assert.Equal(t, "/var/tmp", *ctrl.TmpDir)
+
+	os.Setenv("RADY__HOME_DIR", "/home/rady")
+	defer ResetEnv("RADY__HOME_DIR")
+	assert.Nil(t, app.ReloadValues())
+	assert.Equal(t, "/home/rady", *ctrl.HomeDir)
+
+	ResetEnv("DB_PASSWORD")
+	os.Setenv("RADY_REDIS_PORT", "6379")
+	defer ResetEnv("RADY_REDIS_PORT")
+	assert.Nil(t, app.ReloadValues())
+	assert.Equal(t, "root", *ctrl.Password)
+	assert.Equal(t, int64(6379), *ctrl.RedisPort)
+}
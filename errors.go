@@ -0,0 +1,177 @@
+package rady
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorKind is the kind of a BeanError
+type ErrorKind string
+
+const (
+	// MissingBean means there is no bean to inject
+	MissingBean ErrorKind = "missing bean"
+
+	// AmbiguousBean means there are more than one bean to inject, and none of them is named
+	AmbiguousBean ErrorKind = "ambiguous bean"
+
+	// DuplicateBean means there are more than one prime bean with the same type and name
+	DuplicateBean ErrorKind = "duplicate bean"
+
+	// BadFactory means params or results of a factory are unexpected, or the factory returned an error
+	BadFactory ErrorKind = "bad factory"
+
+	// UnknownValueType means type of a value field cannot be converted from config file
+	UnknownValueType ErrorKind = "unknown value type"
+
+	// MissingValue means a value field of factory param is not loaded
+	MissingValue ErrorKind = "missing value"
+
+	// CircularDependency means a factory depends on its result, directly or indirectly
+	CircularDependency ErrorKind = "circular dependency"
+
+	// HookFailed means a lifetime hook returned an error
+	HookFailed ErrorKind = "hook failed"
+
+	// InvalidBean means a registered bean or factory is unexpected
+	InvalidBean ErrorKind = "invalid bean"
+
+	// ScopeMismatch means a request bean is injected out of request
+	ScopeMismatch ErrorKind = "scope mismatch"
+
+	// BadSchedule means method, cron or interval of a Scheduled field is invalid
+	BadSchedule ErrorKind = "bad schedule"
+
+	// ReloadFailed means config file cannot be parsed in reload, or a recalled factory failed, the reload is rolled back
+	ReloadFailed ErrorKind = "reload failed"
+
+	// BadArgument means a command-line argument is malformed, see Args
+	BadArgument ErrorKind = "bad argument"
+)
+
+type (
+	/*
+		BeanError is a problem found in bootstrap
+
+		Type and Name is the offending bean, Name can be empty when the bean is not named
+
+		Field is the path of the field or param being injected, like `*rady.BookController.RedisComponent` or `GetRedisComponent(#0)`
+
+		Err is the cause, only exists when the problem is returned by user code
+	*/
+	BeanError struct {
+		Kind    ErrorKind
+		Type    reflect.Type
+		Name    string
+		Field   string
+		Message string
+		Err     error
+	}
+
+	// BootErrors is all BeanError collected in bootstrap
+	BootErrors []*BeanError
+)
+
+// NewBeanError is factory function of BeanError
+func NewBeanError(kind ErrorKind, Type reflect.Type, name, field, message string) *BeanError {
+	return &BeanError{
+		Kind:    kind,
+		Type:    Type,
+		Name:    name,
+		Field:   field,
+		Message: message,
+	}
+}
+
+// NewFactoryError is factory function of BeanError, for error returned by factory of the bean
+func NewFactoryError(bean *Bean, err error) *BeanError {
+	beanErr := NewBeanError(BadFactory, bean.Type, bean.Factory.Name, "", err.Error())
+	beanErr.Err = err
+	return beanErr
+}
+
+func (e *BeanError) Error() string {
+	return fmt.Sprintf("%s: %s [type: %s, name: %s, field: %s]", e.Kind, e.Message, e.Type, e.Name, e.Field)
+}
+
+// Unwrap return the cause
+func (e *BeanError) Unwrap() error {
+	return e.Err
+}
+
+func (errs BootErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d error(s) in bootstrap:\n\t%s", len(errs), strings.Join(messages, "\n\t"))
+}
+
+// Unwrap return all errors, so errors.Is and errors.As can find the cause
+func (errs BootErrors) Unwrap() []error {
+	result := make([]error, 0, len(errs))
+	for _, err := range errs {
+		result = append(result, err)
+	}
+	return result
+}
+
+// OfKind return errors of the kind
+func (errs BootErrors) OfKind(kind ErrorKind) BootErrors {
+	result := make(BootErrors, 0)
+	for _, err := range errs {
+		if err.Kind == kind {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// without return errors not of the kind
+func (errs BootErrors) without(kind ErrorKind) BootErrors {
+	result := make(BootErrors, 0, len(errs))
+	for _, err := range errs {
+		if err.Kind != kind {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// contains return true when there is an error with the same kind, type, name and field
+func (errs BootErrors) contains(target *BeanError) bool {
+	for _, err := range errs {
+		if err.Kind == target.Kind && err.Type == target.Type && err.Name == target.Name && err.Field == target.Field {
+			return true
+		}
+	}
+	return false
+}
+
+// addError log and collect a BeanError, the same problem is collected only once
+func (a *Application) addError(err *BeanError) {
+	if a.bootErrors.contains(err) {
+		return
+	}
+	a.Logger.Error(err.Error())
+	a.bootErrors = append(a.bootErrors, err)
+}
+
+// takeErrors remove errors collected after the first count errors and return them, return nil when there is none
+func (a *Application) takeErrors(count int) BootErrors {
+	if len(a.bootErrors) == count {
+		return nil
+	}
+	errs := append(BootErrors{}, a.bootErrors[count:]...)
+	a.bootErrors = a.bootErrors[:count]
+	return errs
+}
+
+// BootError return nil when there is no error in bootstrap, else return BootErrors
+func (a *Application) BootError() error {
+	if len(a.bootErrors) == 0 {
+		return nil
+	}
+	return a.bootErrors
+}
@@ -0,0 +1,91 @@
+package rady
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+var ErrBrokenClient = errors.New("broken client")
+
+type (
+	BrokenRoot struct {
+		*BrokenConfig
+		*BrokenController
+	}
+
+	BrokenConfig struct {
+		Configuration
+	}
+
+	BrokenRepository struct {
+		Repository
+	}
+
+	BrokenComponent struct {
+		Component
+	}
+
+	BrokenController struct {
+		Controller
+		Repo *BrokenRepository `name:"missing"`
+	}
+
+	BrokenClientRoot struct {
+		*BrokenClientConfig
+	}
+
+	BrokenClientConfig struct {
+		Configuration
+	}
+
+	BrokenClient struct {
+		Component
+	}
+)
+
+func (b *BrokenConfig) GetBrokenComponent(size int) *BrokenComponent {
+	return new(BrokenComponent)
+}
+
+func (b *BrokenClientConfig) GetBrokenClient() *BrokenClient {
+	return new(BrokenClient)
+}
+
+func (b *BrokenClient) PostInit() error {
+	return ErrBrokenClient
+}
+
+func TestBuildErrors(t *testing.T) {
+	_, err := CreateApplication(new(BrokenRoot)).Build()
+	var bootErrors BootErrors
+	assert.True(t, errors.As(err, &bootErrors))
+	assert.Len(t, bootErrors, 2)
+
+	missing := bootErrors.OfKind(MissingBean)
+	assert.Len(t, missing, 1)
+	assert.Equal(t, reflect.TypeOf(new(BrokenRepository)), missing[0].Type)
+	assert.Equal(t, "missing", missing[0].Name)
+	assert.Equal(t, "*rady.BrokenController.Repo", missing[0].Field)
+
+	badFactory := bootErrors.OfKind(BadFactory)
+	assert.Len(t, badFactory, 1)
+	assert.Equal(t, reflect.TypeOf(0), badFactory[0].Type)
+	assert.Equal(t, "GetBrokenComponent", badFactory[0].Name)
+	assert.Equal(t, "GetBrokenComponent(#0)", badFactory[0].Field)
+}
+
+func TestHookErrors(t *testing.T) {
+	_, err := CreateApplication(new(BrokenClientRoot)).Build()
+	assert.True(t, errors.Is(err, ErrBrokenClient))
+	hookFailed := err.(BootErrors).OfKind(HookFailed)
+	assert.Len(t, hookFailed, 1)
+	assert.Equal(t, "GetBrokenClient", hookFailed[0].Name)
+}
+
+func TestBuild(t *testing.T) {
+	app, err := CreateApplication(new(LifetimeRoot)).Build()
+	assert.Nil(t, err)
+	assert.Nil(t, app.BootError())
+}
@@ -0,0 +1,217 @@
+package rady
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type (
+	// StartedEvent is published when app is built, all beans are initialized
+	StartedEvent struct {
+		App *Application
+	}
+
+	// ReloadedEvent is published when config file is reloaded by ReloadValues, with keys changed and factories recalled
+	ReloadedEvent struct {
+		App       *Application
+		Keys      []string
+		Factories []string
+	}
+
+	// ShutdownEvent is published when app starts to shutdown, before server is drained
+	ShutdownEvent struct {
+		App *Application
+	}
+)
+
+/*
+Listener is a method of bean subscribing events assignable to EventType
+
+Async is true when the method is called in a new goroutine
+*/
+type Listener struct {
+	Bean      *Bean
+	Method    string
+	EventType reflect.Type
+	Async     bool
+	handler   reflect.Value
+}
+
+/*
+EventPublisher is the event bus of app, it is a bean can be injected by `type:"component"`
+
+methods of singleton beans are subscribed when app is built
+
+	1. methods named like `On<Event>`
+
+	2. methods in `listen` tag of the bean
+
+a listener method has one param, Ptr of struct or Interface, and returns nothing or an error,
+it is called when the published event is assignable to its param, in order of `order` tag of beans, see SortByOrder
+
+listeners matched by patterns in `async` tag of the bean are called in new goroutines, patterns are matched by path.Match
+
+Usage:
+
+	type AuditService struct {
+		Service   `listen:"Audit" async:"On*"`
+		Publisher *EventPublisher `type:"component"`
+	}
+
+	func (s *AuditService) OnStarted(event *StartedEvent) {
+	}
+
+	func (s *AuditService) Audit(event *UserCreated) error {
+		return nil
+	}
+
+	func (s *AuditService) Create(user *User) error {
+		return s.Publisher.Publish(&UserCreated{user})
+	}
+*/
+type EventPublisher struct {
+	listeners []*Listener
+	lock      sync.RWMutex
+	async     sync.WaitGroup
+	logger    *Logger
+}
+
+// NewEventPublisher is factory function of EventPublisher
+func NewEventPublisher(logger *Logger) *EventPublisher {
+	return &EventPublisher{
+		listeners: make([]*Listener, 0),
+		logger:    logger,
+	}
+}
+
+// IsListenerName return true when name is like `On<Event>`
+func IsListenerName(name string) bool {
+	return strings.HasPrefix(name, "On") && len(name) > 2 && unicode.IsUpper(rune(name[2]))
+}
+
+// GetEventType return type of the event a listener method subscribes, false when methodType is not a listener
+func GetEventType(methodType reflect.Type) (reflect.Type, bool) {
+	if methodType.NumIn() != 1 || methodType.NumOut() > 1 || methodType.NumOut() == 1 && methodType.Out(0) != ErrorType {
+		return nil, false
+	}
+	eventType := methodType.In(0)
+	if !CheckPtrOfStruct(eventType) && (eventType.Kind() != reflect.Interface || eventType == ContextType) {
+		return nil, false
+	}
+	return eventType, true
+}
+
+// listenersOf return listener methods of the bean
+func listenersOf(bean *Bean) []*Listener {
+	tagged := make(map[string]bool)
+	for _, name := range GetTagList(bean, "listen") {
+		tagged[name] = true
+	}
+	asyncPatterns := GetTagList(bean, "async")
+
+	listeners := make([]*Listener, 0)
+	beanValue := bean.Value.Addr()
+	for i := 0; i < beanValue.NumMethod(); i++ {
+		name := bean.Type.Method(i).Name
+		if !tagged[name] && !IsListenerName(name) {
+			continue
+		}
+		handler := beanValue.Method(i)
+		eventType, ok := GetEventType(handler.Type())
+		if !ok {
+			continue
+		}
+		listener := &Listener{Bean: bean, Method: name, EventType: eventType, handler: handler}
+		for _, pattern := range asyncPatterns {
+			if matched, _ := path.Match(pattern, name); matched {
+				listener.Async = true
+			}
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners
+}
+
+// Subscribe subscribe listener methods of singleton beans, listeners are sorted by SortByOrder of their beans
+func (p *EventPublisher) Subscribe(beans []*Bean) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, bean := range beans {
+		if IsScoped(bean) || !CheckPtrOfStruct(bean.Type) {
+			continue
+		}
+		for _, listener := range listenersOf(bean) {
+			p.listeners = append(p.listeners, listener)
+			p.logger.Debug("Subscribe %s of [%s][%s] to %s", listener.Method, bean.Type, bean.Name, listener.EventType)
+		}
+	}
+	sort.SliceStable(p.listeners, func(i, j int) bool {
+		orderI, okI := GetOrder(p.listeners[i].Bean)
+		orderJ, okJ := GetOrder(p.listeners[j].Bean)
+		if okI && okJ {
+			return orderI < orderJ
+		}
+		return okI && !okJ
+	})
+}
+
+// Listeners return listeners event is assignable to
+func (p *EventPublisher) Listeners(event interface{}) []*Listener {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	eventType := reflect.TypeOf(event)
+	listeners := make([]*Listener, 0)
+	for _, listener := range p.listeners {
+		if eventType != nil && eventType.AssignableTo(listener.EventType) {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners
+}
+
+/*
+Publish call listeners of event, sync listeners are called in order, async listeners are called in new goroutines
+
+Publish return the first error returned by sync listeners, errors and panics of async listeners are logged
+*/
+func (p *EventPublisher) Publish(event interface{}) error {
+	var firstErr error
+	for _, listener := range p.Listeners(event) {
+		if listener.Async {
+			p.async.Add(1)
+			go func(listener *Listener) {
+				defer p.async.Done()
+				defer func() {
+					if cause := recover(); cause != nil {
+						p.logger.Error("%s of [%s][%s] panics: %v", listener.Method, listener.Bean.Type, listener.Bean.Name, cause)
+					}
+				}()
+				if err := listener.call(event); err != nil {
+					p.logger.Error("%s of [%s][%s] failed: %s", listener.Method, listener.Bean.Type, listener.Bean.Name, err)
+				}
+			}(listener)
+			continue
+		}
+		if err := listener.call(event); err != nil {
+			p.logger.Error("%s of [%s][%s] failed: %s", listener.Method, listener.Bean.Type, listener.Bean.Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Wait block until all async listeners return
+func (p *EventPublisher) Wait() {
+	p.async.Wait()
+}
+
+func (l *Listener) call(event interface{}) error {
+	results := l.handler.Call([]reflect.Value{reflect.ValueOf(event)})
+	return ResultError(results)
+}
@@ -0,0 +1,120 @@
+package rady
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type (
+	EventRoot struct {
+		*EventController
+	}
+
+	UserCreated struct {
+		Name string
+	}
+
+	UserDeleted struct {
+		Name string
+	}
+
+	EventAudit struct {
+		Service `listen:"Audit" async:"OnUser*" order:"2"`
+		Created chan string
+		Audited []string
+	}
+
+	EventCounter struct {
+		Component `order:"1"`
+		Started   int
+		Reloaded  int
+		Shutdown  int
+		Events    []string
+		lock      sync.Mutex
+	}
+
+	EventController struct {
+		Controller
+		Audit     *EventAudit
+		Counter   *EventCounter
+		Publisher *EventPublisher `type:"component"`
+	}
+)
+
+func (a *EventAudit) PreInit() {
+	a.Created = make(chan string, 1)
+}
+
+func (a *EventAudit) OnUserCreated(event *UserCreated) {
+	a.Created <- event.Name
+}
+
+func (a *EventAudit) OnUserDeleted(event *UserDeleted) {
+	panic("user " + event.Name + " cannot be deleted")
+}
+
+func (a *EventAudit) Audit(event *UserCreated) error {
+	a.Audited = append(a.Audited, event.Name)
+	if event.Name == "" {
+		return errors.New("name is empty")
+	}
+	return nil
+}
+
+func (c *EventCounter) OnStarted(event *StartedEvent) {
+	c.Started++
+}
+
+func (c *EventCounter) OnReloaded(event *ReloadedEvent) {
+	c.Reloaded++
+}
+
+func (c *EventCounter) OnShutdown(event *ShutdownEvent) {
+	c.Shutdown++
+}
+
+func (c *EventCounter) OnAny(event interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if created, ok := event.(*UserCreated); ok {
+		c.Events = append(c.Events, created.Name)
+	}
+}
+
+func (c *EventCounter) OnContext(ctx Context) error {
+	return errors.New("handler is not a listener")
+}
+
+func TestEventPublisher(t *testing.T) {
+	app, err := CreateApplication(new(EventRoot)).Build()
+	assert.Nil(t, err)
+	ctrl := app.BeanMap[reflect.TypeOf(new(EventController))]["*rady.EventController"].Value.Addr().Interface().(*EventController)
+	assert.True(t, ctrl.Publisher == app.Publisher)
+	assert.Equal(t, 1, ctrl.Counter.Started)
+
+	listeners := app.Publisher.Listeners(&UserCreated{})
+	assert.Len(t, listeners, 3)
+	assert.Equal(t, "OnAny", listeners[0].Method)
+	assert.True(t, listeners[1].Async || listeners[2].Async)
+
+	assert.Nil(t, ctrl.Publisher.Publish(&UserCreated{Name: "rady"}))
+	assert.Equal(t, "rady", <-ctrl.Audit.Created)
+	assert.Equal(t, []string{"rady"}, ctrl.Audit.Audited)
+	assert.Equal(t, []string{"rady"}, ctrl.Counter.Events)
+
+	assert.Equal(t, "name is empty", ctrl.Publisher.Publish(&UserCreated{}).Error())
+	<-ctrl.Audit.Created
+
+	assert.Nil(t, ctrl.Publisher.Publish(&UserDeleted{Name: "rady"}))
+	ctrl.Publisher.Wait()
+
+	app.ReloadValues()
+	assert.Equal(t, 1, ctrl.Counter.Reloaded)
+
+	assert.Nil(t, app.Shutdown(context.Background()))
+	assert.Equal(t, 1, ctrl.Counter.Shutdown)
+}
@@ -0,0 +1,86 @@
+package rady
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var ErrConnectionRefused = errors.New("connection refused")
+
+type (
+	FactoryRoot struct {
+		*FactoryConfig
+	}
+
+	FactoryConfig struct {
+		Configuration
+	}
+
+	FactoryOptions struct {
+		Host *string `value:"rady.redis.host"`
+		Port *int64  `value:"rady.redis.port"`
+		Pool *int64  `value:"rady.factory.pool" default:"4"`
+	}
+
+	FactoryDB struct {
+		Database
+		Addr string
+		Pool int64
+	}
+
+	FactoryTest struct {
+		Testing
+		DB *FactoryDB
+	}
+
+	BrokenFactoryRoot struct {
+		*BrokenFactoryConfig
+	}
+
+	BrokenFactoryConfig struct {
+		Configuration
+	}
+
+	BrokenFactoryDB struct {
+		Database
+	}
+
+	NilFactoryDB struct {
+		Database
+	}
+)
+
+func (c *FactoryConfig) GetFactoryDB(options *FactoryOptions) (*FactoryDB, error) {
+	if options.Host == nil || options.Port == nil {
+		return nil, errors.New("options are not injected")
+	}
+	return &FactoryDB{Addr: fmt.Sprintf("%s:%d", *options.Host, *options.Port), Pool: *options.Pool}, nil
+}
+
+func (c *BrokenFactoryConfig) GetBrokenFactoryDB() (*BrokenFactoryDB, error) {
+	return nil, ErrConnectionRefused
+}
+
+func (c *BrokenFactoryConfig) GetNilFactoryDB() *NilFactoryDB {
+	return nil
+}
+
+func (f *FactoryTest) TestFactory(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:6937", f.DB.Addr)
+	assert.Equal(t, int64(4), f.DB.Pool)
+}
+
+func TestFactory(t *testing.T) {
+	CreateTest(new(FactoryRoot)).AddTest(new(FactoryTest)).Test(t)
+}
+
+func TestFactoryErrors(t *testing.T) {
+	_, err := CreateApplication(new(BrokenFactoryRoot)).Build()
+	badFactory := err.(BootErrors).OfKind(BadFactory)
+	assert.Len(t, badFactory, 2)
+	assert.True(t, errors.Is(err, ErrConnectionRefused))
+	assert.Equal(t, "GetBrokenFactoryDB", badFactory[0].Name)
+	assert.Equal(t, "result of GetNilFactoryDB is nil", badFactory[1].Message)
+}
@@ -0,0 +1,64 @@
+package rady
+
+import (
+	"fmt"
+	"github.com/tidwall/gjson"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// SetFlag is the command-line flag to override a config value, like `--set rady.server.addr=:9000`
+const SetFlag = "--set"
+
+/*
+Args parse `--set key=value` and `--set=key=value` in args into Overrides, other args are ignored
+
+overrides take precedence over config file, but not over env (see GetEnvName), values are parsed like env,
+Args should be called before Build, Run and Start parse os.Args[1:] when it isn't called;
+overrides and BadArgument errors of the last call are dropped when it is called again
+
+Usage:
+
+	CreateTest(new(Root)).Args([]string{"--set", "rady.server.addr=:9000"}).AddTest(new(ServerTest)).Test(t)
+*/
+func (a *Application) Args(args []string) *Application {
+	a.Overrides = make(map[string]gjson.Result)
+	a.bootErrors = a.bootErrors.without(BadArgument)
+	for i := 0; i < len(args); i++ {
+		var override string
+		switch {
+		case args[i] == SetFlag:
+			if i++; i == len(args) {
+				a.addError(NewBeanError(BadArgument, reflect.TypeOf(a), SetFlag, "", "the flag needs a value like key=value"))
+				return a
+			}
+			override = args[i]
+		case strings.HasPrefix(args[i], SetFlag+"="):
+			override = strings.TrimPrefix(args[i], SetFlag+"=")
+		default:
+			continue
+		}
+		pair := strings.SplitN(override, "=", 2)
+		key := strings.Trim(pair[0], " ")
+		if len(pair) != 2 || key == "" {
+			a.addError(NewBeanError(BadArgument, reflect.TypeOf(a), override, "", fmt.Sprintf("value of %s should be like key=value", SetFlag)))
+			continue
+		}
+		a.Overrides[key] = parseValue(pair[1])
+		a.Logger.Debug("Override '%s' by %s: %s", key, SetFlag, pair[1])
+	}
+	if a.ConfigFile != "" {
+		// placeholders referring overridden keys are resolved again
+		a.loadConfigFile()
+	}
+	return a
+}
+
+// parseArgs parse os.Args[1:] by Args when Args isn't called
+func (a *Application) parseArgs() *Application {
+	if a.Overrides == nil {
+		return a.Args(os.Args[1:])
+	}
+	return a
+}
@@ -0,0 +1,67 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"reflect"
+	"testing"
+)
+
+type (
+	FlagRoot struct {
+		CONF `path:"./resources/application.yaml"`
+		*FlagController
+	}
+
+	FlagController struct {
+		Controller
+		RedisHost *string  `value:"rady.redis.host"`
+		RedisPort *int64   `value:"rady.redis.port"`
+		Ports     *[]int64 `value:"rady.server.ports"`
+	}
+)
+
+func TestArgs(t *testing.T) {
+	os.Setenv("RADY_REDIS_PORT", "6379")
+	defer ResetEnv("RADY_REDIS_PORT")
+
+	app, err := CreateApplication(new(FlagRoot)).Args([]string{
+		"-v",
+		"--set", "rady.server.addr=:9000",
+		"--set=rady.redis.host=redis",
+		"--set", "rady.redis.port=1200",
+		"--set", "rady.server.ports=[8080, 8443]",
+	}).Build()
+	assert.Nil(t, err)
+	ctrl := app.BeanMap[reflect.TypeOf(new(FlagController))]["*rady.FlagController"].Value.Addr().Interface().(*FlagController)
+	assert.Equal(t, ":9000", *app.Addr)
+	assert.Equal(t, "redis", *ctrl.RedisHost)
+	assert.Equal(t, int64(6379), *ctrl.RedisPort)
+	assert.Equal(t, []int64{8080, 8443}, *ctrl.Ports)
+}
+
+func TestBadArgs(t *testing.T) {
+	_, err := CreateApplication(new(FlagRoot)).Args([]string{"--set", "rady.redis.host", "--set"}).Build()
+	assert.NotNil(t, err)
+	assert.Len(t, err.(BootErrors).OfKind(BadArgument), 2)
+
+	app := CreateApplication(new(FlagRoot)).Args([]string{"--set", "rady.redis.host"})
+	assert.Len(t, app.bootErrors.OfKind(BadArgument), 1)
+	_, err = app.Args([]string{"--set", "rady.redis.host=redis"}).Build()
+	assert.Nil(t, err)
+}
+
+func TestParseArgs(t *testing.T) {
+	args := os.Args
+	os.Args = []string{"rady", "--set", "rady.redis.host=redis"}
+	defer func() {
+		os.Args = args
+	}()
+
+	app := CreateApplication(new(FlagRoot))
+	assert.Nil(t, app.Overrides)
+	assert.Equal(t, "redis", app.parseArgs().Overrides["rady.redis.host"].String())
+
+	app = CreateApplication(new(FlagRoot)).Args([]string{"--set", "rady.redis.host=localhost"})
+	assert.Equal(t, "localhost", app.parseArgs().Overrides["rady.redis.host"].String())
+}
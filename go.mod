@@ -1,6 +1,9 @@
 module github.com/rady-io/inject
 
+go 1.20
+
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/ghodss/yaml v1.0.0
 	github.com/kr/pretty v0.1.0 // indirect
@@ -10,6 +13,7 @@ require (
 	github.com/mattn/go-isatty v0.0.4 // indirect
 	github.com/op/go-logging v0.0.0-20160211212156-b2cb9fa56473
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.2.2
 	github.com/tidwall/gjson v1.1.3
 	github.com/tidwall/match v0.0.0-20171002075945-1731857f09b1 // indirect
@@ -0,0 +1,275 @@
+package rady
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/*
+Dependency is an edge of the dependency graph
+
+Path is path of the field or factory param the Bean is injected into, like `*rady.B.A` or `GetA(#0)`
+
+Param is true when Bean is passed to factory, the factory cannot be called before Bean is ready,
+and Field is the field or slice element Bean is set into, or the map when Key is valid, invalid for factory params
+*/
+type Dependency struct {
+	Bean  *Bean
+	Path  string
+	Param bool
+	Field reflect.Value
+	Key   reflect.Value
+}
+
+// NewDependency is factory function of Dependency
+func NewDependency(bean *Bean, path string, param bool) *Dependency {
+	return &Dependency{
+		Bean:  bean,
+		Path:  path,
+		Param: param,
+	}
+}
+
+// Inject set value into the field, or the map with Key
+func (d *Dependency) Inject(value reflect.Value) {
+	if d.Key.IsValid() {
+		d.Field.SetMapIndex(d.Key, value)
+		return
+	}
+	d.Field.Set(value)
+}
+
+// FieldType return type of value can be injected by Inject
+func (d *Dependency) FieldType() reflect.Type {
+	if d.Key.IsValid() {
+		return d.Field.Type().Elem()
+	}
+	return d.Field.Type()
+}
+
+// sortedBeans return all beans in BeanMap, sorted by type and name
+func (a *Application) sortedBeans() []*Bean {
+	beans := make([]*Bean, 0)
+	for _, nameMap := range a.BeanMap {
+		for _, bean := range nameMap {
+			beans = append(beans, bean)
+		}
+	}
+	sort.Slice(beans, func(i, j int) bool {
+		if beans[i].Type != beans[j].Type {
+			return beans[i].Type.String() < beans[j].Type.String()
+		}
+		return beans[i].Name < beans[j].Name
+	})
+	return beans
+}
+
+/*
+dependencyOrder return singleton beans in BeanMap and instances injected into them, every bean comes after its Deps
+
+beans injected into each other by fields are ordered by type and name, cycles through factory params are reported by checkCycles
+*/
+func (a *Application) dependencyOrder() []*Bean {
+	order := make([]*Bean, 0)
+	visited := make(map[*Bean]bool)
+	var visit func(bean *Bean)
+	visit = func(bean *Bean) {
+		if visited[bean] {
+			return
+		}
+		visited[bean] = true
+		for _, dep := range bean.Deps {
+			visit(dep.Bean)
+		}
+		order = append(order, bean)
+	}
+	for _, bean := range a.sortedBeans() {
+		if !IsScoped(bean) {
+			visit(bean)
+		}
+	}
+	return order
+}
+
+// orderBeans sort beans in dependency order, Deps out of beans are ignored
+func orderBeans(beans []*Bean) []*Bean {
+	contained := make(map[*Bean]bool)
+	for _, bean := range beans {
+		contained[bean] = true
+	}
+	order := make([]*Bean, 0, len(beans))
+	visited := make(map[*Bean]bool)
+	var visit func(bean *Bean)
+	visit = func(bean *Bean) {
+		if visited[bean] || !contained[bean] {
+			return
+		}
+		visited[bean] = true
+		for _, dep := range bean.Deps {
+			visit(dep.Bean)
+		}
+		order = append(order, bean)
+	}
+	for _, bean := range beans {
+		visit(bean)
+	}
+	return order
+}
+
+/*
+checkCycles collect a BeanError for every strongly connected group of beans containing a factory param
+
+beans injected into each other only by fields are allowed, because fields are set after all beans are created
+*/
+func (a *Application) checkCycles() {
+	beans := a.sortedBeans()
+	groups := StronglyConnect(beans)
+	reported := make(map[int]bool)
+	for _, bean := range beans {
+		for _, dep := range bean.Deps {
+			group := groups[bean]
+			if dep.Param && group == groups[dep.Bean] && !reported[group] {
+				reported[group] = true
+				cycle := append([]*Dependency{NewDependency(bean, "", false), dep}, FindPath(dep.Bean, bean, groups)...)
+				a.addError(NewBeanError(CircularDependency, bean.Type, bean.Name, dep.Path, fmt.Sprintf("factory depends on itself: %s", FormatCycle(cycle))))
+			}
+		}
+	}
+}
+
+/*
+checkPrototypeCycles collect a BeanError for every prototype bean depending on itself only through prototype beans,
+which would be instantiated endlessly, return false when there is any
+*/
+func (a *Application) checkPrototypeCycles(beans []*Bean) bool {
+	groups := make(map[*Bean]int)
+	for _, bean := range beans {
+		if GetScope(bean) == Prototype {
+			groups[bean] = 1
+		}
+	}
+
+	ok := true
+	reported := make(map[*Bean]bool)
+	for _, bean := range beans {
+		if groups[bean] != 1 || reported[bean] {
+			continue
+		}
+		for _, dep := range bean.Deps {
+			if groups[dep.Bean] != 1 {
+				continue
+			}
+			path := FindPath(dep.Bean, bean, groups)
+			if dep.Bean != bean && len(path) == 0 {
+				continue
+			}
+			cycle := append([]*Dependency{NewDependency(bean, "", false), dep}, path...)
+			for _, member := range cycle {
+				reported[member.Bean] = true
+			}
+			a.addError(NewBeanError(CircularDependency, bean.Type, bean.Name, dep.Path, fmt.Sprintf("prototype depends on itself: %s", FormatCycle(cycle))))
+			ok = false
+			break
+		}
+	}
+	return ok
+}
+
+// StronglyConnect return index of strongly connected group for each bean, by Tarjan's algorithm
+func StronglyConnect(beans []*Bean) map[*Bean]int {
+	var (
+		index   = 0
+		group   = 0
+		indexes = make(map[*Bean]int)
+		lowLink = make(map[*Bean]int)
+		onStack = make(map[*Bean]bool)
+		stack   = make([]*Bean, 0)
+		groups  = make(map[*Bean]int)
+		connect func(bean *Bean)
+	)
+
+	connect = func(bean *Bean) {
+		indexes[bean] = index
+		lowLink[bean] = index
+		index++
+		stack = append(stack, bean)
+		onStack[bean] = true
+
+		for _, dep := range bean.Deps {
+			if _, ok := indexes[dep.Bean]; !ok {
+				connect(dep.Bean)
+				if lowLink[dep.Bean] < lowLink[bean] {
+					lowLink[bean] = lowLink[dep.Bean]
+				}
+			} else if onStack[dep.Bean] && indexes[dep.Bean] < lowLink[bean] {
+				lowLink[bean] = indexes[dep.Bean]
+			}
+		}
+
+		if lowLink[bean] == indexes[bean] {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				groups[top] = group
+				if top == bean {
+					break
+				}
+			}
+			group++
+		}
+	}
+
+	for _, bean := range beans {
+		if _, ok := indexes[bean]; !ok {
+			connect(bean)
+		}
+	}
+	return groups
+}
+
+// FindPath return the shortest dependencies from `from` to `to` inside the same strongly connected group
+func FindPath(from, to *Bean, groups map[*Bean]int) []*Dependency {
+	if from == to {
+		return []*Dependency{}
+	}
+	via := make(map[*Bean]*Dependency)
+	prev := make(map[*Bean]*Bean)
+	queue := []*Bean{from}
+	visited := map[*Bean]bool{from: true}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range current.Deps {
+			if visited[dep.Bean] || groups[dep.Bean] != groups[from] {
+				continue
+			}
+			visited[dep.Bean] = true
+			via[dep.Bean] = dep
+			prev[dep.Bean] = current
+			if dep.Bean == to {
+				path := make([]*Dependency, 0)
+				for bean := to; bean != from; bean = prev[bean] {
+					path = append([]*Dependency{via[bean]}, path...)
+				}
+				return path
+			}
+			queue = append(queue, dep.Bean)
+		}
+	}
+	return []*Dependency{}
+}
+
+// FormatCycle format dependencies like `[*rady.A][a] -> GetA(#0) -> [*rady.B][b] -> *rady.B.A -> [*rady.A][a]`
+func FormatCycle(cycle []*Dependency) string {
+	parts := make([]string, 0)
+	for i, dep := range cycle {
+		if i > 0 {
+			parts = append(parts, dep.Path)
+		}
+		parts = append(parts, fmt.Sprintf("[%s][%s]", dep.Bean.Type, dep.Bean.Name))
+	}
+	return strings.Join(parts, " -> ")
+}
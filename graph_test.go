@@ -0,0 +1,96 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type (
+	GraphRoot struct {
+		*GraphConfig
+	}
+
+	GraphConfig struct {
+		Configuration
+	}
+
+	GraphDB struct {
+		Component
+		Ready bool
+	}
+
+	GraphCacheParam struct {
+		Parameter
+		DB *GraphDB
+	}
+
+	GraphCache struct {
+		Component
+		DBReady bool
+	}
+
+	GraphPing struct {
+		Service
+		Pong *GraphPong
+	}
+
+	GraphPong struct {
+		Service
+		Ping *GraphPing
+	}
+
+	GraphTest struct {
+		Testing
+		Cache *GraphCache
+		Ping  *GraphPing
+	}
+
+	CycleRoot struct {
+		*CycleConfig
+	}
+
+	CycleConfig struct {
+		Configuration
+	}
+
+	CycleA struct {
+		Component
+	}
+
+	CycleB struct {
+		Component
+		A *CycleA
+	}
+)
+
+func (g *GraphConfig) GetGraphCache(param *GraphCacheParam) *GraphCache {
+	return &GraphCache{DBReady: param.DB.Ready}
+}
+
+func (g *GraphConfig) GetGraphDB() *GraphDB {
+	return &GraphDB{Ready: true}
+}
+
+func (c *CycleConfig) GetCycleA(b *CycleB) *CycleA {
+	return new(CycleA)
+}
+
+func (g *GraphTest) TestFactoryOrder(t *testing.T) {
+	assert.True(t, g.Cache.DBReady)
+}
+
+func (g *GraphTest) TestFieldCycle(t *testing.T) {
+	assert.Equal(t, g.Ping, g.Ping.Pong.Ping)
+}
+
+func TestDependencyGraph(t *testing.T) {
+	CreateTest(new(GraphRoot)).AddTest(new(GraphTest)).Test(t)
+}
+
+func TestCircularDependency(t *testing.T) {
+	_, err := CreateApplication(new(CycleRoot)).Build()
+	cycles := err.(BootErrors).OfKind(CircularDependency)
+	assert.Len(t, cycles, 1)
+	assert.Equal(t, "GetCycleA", cycles[0].Name)
+	assert.Contains(t, cycles[0].Message, "[*rady.CycleA][GetCycleA] -> GetCycleA(#0) -> [*rady.CycleB][*rady.CycleB] -> *rady.CycleB.A -> [*rady.CycleA][GetCycleA]")
+}
@@ -0,0 +1,375 @@
+package rady
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Implements return beans whose type implements interfaceType, sorted by type and name
+
+beans of the framework, whose Source is SourceApplication, are skipped,
+so they never make interface and collection injection ambiguous, they can still be injected by their types
+*/
+func (a *Application) Implements(interfaceType reflect.Type) []*Bean {
+	beans := make([]*Bean, 0)
+	for _, bean := range a.sortedBeans() {
+		if bean.Source != SourceApplication && bean.Type.Implements(interfaceType) {
+			beans = append(beans, bean)
+		}
+	}
+	return beans
+}
+
+// IsPrimary return true when tag of the bean or its component tag has `primary:"true"`
+func IsPrimary(bean *Bean) bool {
+	return bean.Tag.Get("primary") == "true" || GetComponentTag(bean.Type, "primary") == "true"
+}
+
+/*
+assembleInterface inject the bean implements the interface into field
+
+	1. field has a name, inject the bean with the name
+
+	2. there is only one bean implements the interface, inject it
+
+	3. there are more than one, inject the only one tagged `primary:"true"`
+
+the mother bean is never injected into itself
+*/
+func (a *Application) assembleInterface(mother *Bean, value reflect.Value, field reflect.StructField) {
+	fieldPath := GetFieldPath(mother.Type, field)
+	bean, err := a.resolveInterface(mother, field, fieldPath)
+	if err != nil {
+		if err.Kind != MissingBean || !IsOptional(field) {
+			a.addError(err)
+		}
+		return
+	}
+	a.injectBean(mother, bean, value, field, fieldPath)
+}
+
+// resolveInterface find the bean to inject into an Interface field
+func (a *Application) resolveInterface(mother *Bean, field reflect.StructField, fieldPath string) (*Bean, *BeanError) {
+	candidates := make([]*Bean, 0)
+	for _, bean := range a.Implements(field.Type) {
+		if bean != mother {
+			candidates = append(candidates, bean)
+		}
+	}
+
+	if name := field.Tag.Get("name"); name != "" {
+		for _, bean := range candidates {
+			if bean.Name == name {
+				return bean, nil
+			}
+		}
+		return nil, NewBeanError(MissingBean, field.Type, name, fieldPath, "there is no bean with this name implements the interface")
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, NewBeanError(MissingBean, field.Type, "", fieldPath, "there is no bean implements the interface")
+	case 1:
+		return candidates[0], nil
+	default:
+		primaries := make([]*Bean, 0)
+		for _, bean := range candidates {
+			if IsPrimary(bean) {
+				primaries = append(primaries, bean)
+			}
+		}
+		if len(primaries) == 1 {
+			return primaries[0], nil
+		}
+		return nil, NewBeanError(AmbiguousBean, field.Type, "", fieldPath, fmt.Sprintf("beans %s implement the interface, please name it or mark one as primary", FormatBeans(candidates)))
+	}
+}
+
+/*
+GetOrder return value of `order` in tag of the bean or its component tag
+
+beans without valid order are ordered after all ordered beans
+*/
+func GetOrder(bean *Bean) (int, bool) {
+	orderStr := bean.Tag.Get("order")
+	if orderStr == "" {
+		orderStr = GetComponentTag(bean.Type, "order")
+	}
+	order, err := strconv.Atoi(strings.Trim(orderStr, " "))
+	return order, err == nil
+}
+
+// SortByOrder sort beans by GetOrder stably
+func SortByOrder(beans []*Bean) {
+	sort.SliceStable(beans, func(i, j int) bool {
+		orderI, okI := GetOrder(beans[i])
+		orderJ, okJ := GetOrder(beans[j])
+		if okI && okJ {
+			return orderI < orderJ
+		}
+		return okI && !okJ
+	})
+}
+
+/*
+assembleCollection inject all matched beans into a slice or map field
+
+slice is ordered by SortByOrder, map is keyed by bean name
+
+matched beans are beans with the same type as element, or implement the element interface
+*/
+func (a *Application) assembleCollection(mother *Bean, value reflect.Value, field reflect.StructField) {
+	fieldPath := GetFieldPath(mother.Type, field)
+	elemType := field.Type.Elem()
+	beans := make([]*Bean, 0)
+	if elemType.Kind() == reflect.Interface {
+		for _, bean := range a.Implements(elemType) {
+			if bean != mother {
+				beans = append(beans, bean)
+			}
+		}
+	} else {
+		for _, bean := range a.sortedBeans() {
+			if bean.Type == elemType {
+				beans = append(beans, bean)
+			}
+		}
+	}
+
+	instances := make([]*Bean, 0, len(beans))
+	for _, bean := range beans {
+		instance, err := a.scopedBean(mother, bean, fieldPath)
+		if err != nil {
+			a.addError(err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	beans = instances
+
+	deps := make([]*Dependency, 0, len(beans))
+	if field.Type.Kind() == reflect.Slice {
+		SortByOrder(beans)
+		collection := reflect.MakeSlice(field.Type, 0, len(beans))
+		for _, bean := range beans {
+			collection = reflect.Append(collection, bean.Expose(elemType))
+		}
+		value.Set(collection)
+		for i, bean := range beans {
+			dep := NewDependency(bean, fieldPath, false)
+			dep.Field = value.Index(i)
+			deps = append(deps, dep)
+		}
+	} else {
+		collection := reflect.MakeMapWithSize(field.Type, len(beans))
+		value.Set(collection)
+		for _, bean := range beans {
+			dep := NewDependency(bean, fieldPath, false)
+			dep.Field = value
+			dep.Key = reflect.ValueOf(bean.Name).Convert(field.Type.Key())
+			dep.Inject(bean.Expose(elemType))
+			deps = append(deps, dep)
+		}
+	}
+
+	for _, dep := range deps {
+		mother.Deps = append(mother.Deps, dep)
+		a.logAssembleBean(mother.Name, mother.Type.String(), dep.Bean.Name, dep.Bean.Type.String(), field.Name)
+	}
+}
+
+/*
+assembleProvider inject a provider `func() T` or `func() (T, error)` into field, T is resolved when the provider is called first time
+
+if T is Ptr and there is no bean of T, a new bean is loaded lazily, see lazyLoad
+
+provider returns nil when T is missing and field is optional, `func() T` panics when T cannot be resolved
+
+a new instance is returned by every call when T is prototype bean, request bean can only be provided by `func(Context) T` in request
+*/
+func (a *Application) assembleProvider(mother *Bean, value reflect.Value, field reflect.StructField) {
+	var (
+		fieldPath = GetFieldPath(mother.Type, field)
+		target    = GetProviderTarget(field)
+		lock      sync.Mutex
+		resolved  *Bean
+	)
+
+	provider := func(args []reflect.Value) []reflect.Value {
+		lock.Lock()
+		var err error
+		if resolved == nil {
+			resolved, err = a.provide(mother, target, fieldPath)
+		}
+		bean := resolved
+		lock.Unlock()
+
+		if bean != nil && IsScoped(bean) {
+			var scope *RequestScope
+			if len(args) == 1 {
+				c, _ := args[0].Interface().(Context)
+				scope = GetRequestScope(c)
+			}
+			bean, err = a.provideScoped(bean, scope, fieldPath)
+		}
+
+		result := reflect.New(target.Type).Elem()
+		if bean != nil {
+			result.Set(bean.Expose(target.Type))
+		}
+		if field.Type.NumOut() == 1 {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{result}
+		}
+
+		errValue := reflect.New(ErrorType).Elem()
+		if err != nil {
+			errValue.Set(reflect.ValueOf(err))
+		}
+		return []reflect.Value{result, errValue}
+	}
+
+	value.Set(reflect.MakeFunc(field.Type, provider))
+	a.Logger.Debug("Field %s of [%s][%s] set provider of %s", field.Name, mother.Type, mother.Name, target.Type)
+}
+
+/*
+provide resolve the bean for a provider
+
+lazyLock is only held while beans are loaded and resolved, new beans are initialized after unlocking,
+so their hooks can call providers and get beans of the application
+*/
+func (a *Application) provide(mother *Bean, target reflect.StructField, fieldPath string) (*Bean, error) {
+	a.lazyLock.Lock()
+	var (
+		bean     *Bean
+		beanErr  *BeanError
+		newBeans []*Bean
+	)
+	if target.Type.Kind() == reflect.Interface {
+		bean, beanErr = a.resolveInterface(mother, target, fieldPath)
+	} else {
+		if len(a.BeanMap[target.Type]) == 0 && target.Tag.Get("name") == "" {
+			var err error
+			if newBeans, err = a.lazyLoad(target.Type, target.Tag, fieldPath); err != nil {
+				a.lazyLock.Unlock()
+				return nil, err
+			}
+		}
+		bean, beanErr = a.resolveBean(target, fieldPath)
+	}
+	processors := a.processors()
+	a.lazyLock.Unlock()
+
+	if beanErr != nil {
+		a.unloadBeans(newBeans)
+		if beanErr.Kind == MissingBean && IsOptional(target) {
+			return nil, nil
+		}
+		return nil, beanErr
+	}
+	if err := a.initLazyBeans(newBeans, processors); err != nil {
+		return nil, err
+	}
+	return bean, nil
+}
+
+/*
+lazyLoad load a bean of fieldType and its children, then assemble the new beans and return them in dependency order
+
+new beans are removed and errors are returned, when any problem is found
+*/
+func (a *Application) lazyLoad(fieldType reflect.Type, tag reflect.StructTag, fieldPath string) ([]*Bean, error) {
+	count := len(a.bootErrors)
+	loaded := make(map[*Bean]bool)
+	for _, bean := range a.sortedBeans() {
+		loaded[bean] = true
+	}
+	for _, bean := range a.dependencyOrder() {
+		loaded[bean] = true
+	}
+
+	a.loadBean(fieldType, reflect.New(fieldType.Elem()).Elem(), tag, fieldPath)
+	a.loadBeanChild()
+	newBeans := make([]*Bean, 0)
+	for _, bean := range a.sortedBeans() {
+		if !loaded[bean] {
+			newBeans = append(newBeans, bean)
+		}
+	}
+	a.assembleBeans(newBeans)
+
+	if errs := a.takeErrors(count); errs != nil {
+		a.removeBeans(newBeans)
+		return nil, errs
+	}
+	toInit := make([]*Bean, 0)
+	for _, bean := range a.dependencyOrder() {
+		if !loaded[bean] {
+			toInit = append(toInit, bean)
+		}
+	}
+	return toInit, nil
+}
+
+// initLazyBeans initialize beans loaded lazily without lazyLock, then subscribe their listeners and schedule their tasks
+func (a *Application) initLazyBeans(beans, processors []*Bean) error {
+	if len(beans) == 0 {
+		return nil
+	}
+	if err := a.initBeans(beans, processors); err != nil {
+		a.Logger.Error(err.Error())
+		a.unloadBeans(beans)
+		return BootErrors{err}
+	}
+
+	a.lazyLock.Lock()
+	count := len(a.bootErrors)
+	tasks := a.loadTasks(beans)
+	errs := a.takeErrors(count)
+	if errs != nil {
+		a.removeBeans(beans)
+	}
+	a.lazyLock.Unlock()
+	if errs != nil {
+		return errs
+	}
+	a.Publisher.Subscribe(beans)
+	a.Scheduler.Schedule(tasks)
+	return nil
+}
+
+// unloadBeans remove beans loaded lazily with lazyLock held
+func (a *Application) unloadBeans(beans []*Bean) {
+	if len(beans) == 0 {
+		return
+	}
+	a.lazyLock.Lock()
+	defer a.lazyLock.Unlock()
+	a.removeBeans(beans)
+}
+
+// removeBeans remove beans from BeanMap, instances injected into beans are skipped because they are not in BeanMap
+func (a *Application) removeBeans(beans []*Bean) {
+	for _, bean := range beans {
+		if a.BeanMap[bean.Type][bean.Name] == bean {
+			delete(a.BeanMap[bean.Type], bean.Name)
+		}
+	}
+}
+
+// FormatBeans format beans like `[*rady.A][a], [*rady.B][b]`
+func FormatBeans(beans []*Bean) string {
+	parts := make([]string, 0, len(beans))
+	for _, bean := range beans {
+		parts = append(parts, fmt.Sprintf("[%s][%s]", bean.Type, bean.Name))
+	}
+	return strings.Join(parts, ", ")
+}
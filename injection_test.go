@@ -0,0 +1,317 @@
+package rady
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type (
+	Greeter interface {
+		Greet() string
+	}
+
+	Farewell interface {
+		Bye() string
+	}
+
+	Validator interface {
+		Validate(value string) bool
+	}
+
+	InterfaceRoot struct {
+		*InterfaceConfig
+	}
+
+	InterfaceConfig struct {
+		Configuration
+	}
+
+	EnglishGreeter struct {
+		Component `primary:"true"`
+	}
+
+	ChineseGreeter struct {
+		Component
+	}
+
+	GermanFarewell struct {
+		Component
+	}
+
+	InterfaceTest struct {
+		Testing
+		Greeter Greeter `type:"component"`
+		Chinese Greeter `type:"component" name:"GetChineseGreeter"`
+	}
+
+	CollectionRoot struct {
+		*CollectionConfig
+	}
+
+	CollectionConfig struct {
+		Configuration
+	}
+
+	NotEmptyValidator struct {
+		Component `order:"2"`
+	}
+
+	LengthValidator struct {
+		Component `order:"1"`
+		Max       int
+	}
+
+	CollectionStore struct {
+		Repository
+		Table string
+	}
+
+	CollectionTest struct {
+		Testing
+		Validators []Validator `type:"component"`
+		Stores     map[string]*CollectionStore
+		Empty      []Greeter `type:"component"`
+	}
+
+	AmbiguousRoot struct {
+		*AmbiguousConfig
+		*AmbiguousController
+	}
+
+	AmbiguousConfig struct {
+		Configuration
+	}
+
+	AmbiguousController struct {
+		Controller
+		Farewell Farewell `type:"service"`
+	}
+
+	OptionalRoot struct {
+		*OptionalConfig
+	}
+
+	OptionalConfig struct {
+		Configuration
+	}
+
+	LazyRepository struct {
+		Repository
+	}
+
+	LazyService struct {
+		Service
+		Repo    *LazyRepository
+		Created bool
+	}
+
+	BrokenLazyService struct {
+		Service
+		Tries int
+	}
+
+	OptionalTest struct {
+		Testing
+		Missing   *LazyRepository `optional:"true" name:"nothing"`
+		Farewell  Farewell        `type:"component" optional:"true"`
+		Service   func() *LazyService
+		Broken    func() (*BrokenLazyService, error)
+		Farewells func() Farewell `type:"component" optional:"true"`
+	}
+)
+
+func (s *LazyService) PreInit() {
+	s.Created = s.Repo != nil
+}
+
+func (s *BrokenLazyService) PostInit() error {
+	s.Tries++
+	return errors.New("not ready")
+}
+
+func (c *CollectionConfig) GetNotEmptyValidator() *NotEmptyValidator {
+	return new(NotEmptyValidator)
+}
+
+func (c *CollectionConfig) GetLengthValidator() *LengthValidator {
+	return &LengthValidator{Max: 5}
+}
+
+func (c *CollectionConfig) GetUserStore() *CollectionStore {
+	return &CollectionStore{Table: "users"}
+}
+
+func (c *CollectionConfig) GetBookStore() *CollectionStore {
+	return &CollectionStore{Table: "books"}
+}
+
+func (v *NotEmptyValidator) Validate(value string) bool {
+	return value != ""
+}
+
+func (v *LengthValidator) Validate(value string) bool {
+	return len(value) <= v.Max
+}
+
+func (c *InterfaceConfig) GetEnglishGreeter() *EnglishGreeter {
+	return new(EnglishGreeter)
+}
+
+func (c *InterfaceConfig) GetChineseGreeter() *ChineseGreeter {
+	return new(ChineseGreeter)
+}
+
+func (c *AmbiguousConfig) GetGermanFarewell() *GermanFarewell {
+	return new(GermanFarewell)
+}
+
+func (c *AmbiguousConfig) GetChineseGreeter() *ChineseGreeter {
+	return new(ChineseGreeter)
+}
+
+func (e *EnglishGreeter) Greet() string {
+	return "Hello"
+}
+
+func (g *GermanFarewell) Bye() string {
+	return "Tschüss"
+}
+
+func (c *ChineseGreeter) Greet() string {
+	return "你好"
+}
+
+func (c *ChineseGreeter) Bye() string {
+	return "再见"
+}
+
+func (i *InterfaceTest) TestInterfaceInject(t *testing.T) {
+	assert.Equal(t, "Hello", i.Greeter.Greet())
+	assert.Equal(t, "你好", i.Chinese.Greet())
+}
+
+func (c *CollectionTest) TestCollectionInject(t *testing.T) {
+	assert.Len(t, c.Validators, 2)
+	assert.IsType(t, new(LengthValidator), c.Validators[0])
+	assert.IsType(t, new(NotEmptyValidator), c.Validators[1])
+	assert.Equal(t, "users", c.Stores["GetUserStore"].Table)
+	assert.Equal(t, "books", c.Stores["GetBookStore"].Table)
+	assert.NotNil(t, c.Empty)
+	assert.Empty(t, c.Empty)
+}
+
+func TestCollectionInjection(t *testing.T) {
+	CreateTest(new(CollectionRoot)).AddTest(new(CollectionTest)).Test(t)
+}
+
+func TestInterfaceInjection(t *testing.T) {
+	CreateTest(new(InterfaceRoot)).AddTest(new(InterfaceTest)).Test(t)
+
+	_, err := CreateApplication(new(AmbiguousRoot)).Build()
+	ambiguous := err.(BootErrors).OfKind(AmbiguousBean)
+	assert.Len(t, ambiguous, 1)
+	assert.Equal(t, "*rady.AmbiguousController.Farewell", ambiguous[0].Field)
+	assert.Contains(t, ambiguous[0].Message, "[*rady.ChineseGreeter][GetChineseGreeter], [*rady.GermanFarewell][GetGermanFarewell]")
+}
+
+func (o *OptionalTest) TestOptionalInject(t *testing.T) {
+	assert.Nil(t, o.Missing)
+	assert.Nil(t, o.Farewell)
+	assert.Nil(t, o.Farewells())
+}
+
+func (o *OptionalTest) TestLazyInject(t *testing.T) {
+	service := o.Service()
+	assert.NotNil(t, service)
+	assert.True(t, service.Created)
+	assert.True(t, service == o.Service())
+
+	broken, err := o.Broken()
+	assert.Nil(t, broken)
+	assert.IsType(t, BootErrors{}, err)
+	assert.Len(t, err.(BootErrors).OfKind(HookFailed), 1)
+	_, err = o.Broken()
+	assert.NotNil(t, err)
+}
+
+func TestOptionalInjection(t *testing.T) {
+	CreateTest(new(OptionalRoot)).AddTest(new(OptionalTest)).Test(t)
+}
+
+type LazyConsumer struct {
+	Service func() *LazyService
+}
+
+func TestLazyLoadConcurrently(t *testing.T) {
+	consumer := new(LazyConsumer)
+	app, err := CreateApplication(new(OptionalRoot)).Register(consumer, "").Build()
+	assert.Nil(t, err)
+
+	started, stop, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		close(started)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				app.Describe()
+			}
+		}
+	}()
+	<-started
+	assert.True(t, consumer.Service().Created)
+	close(stop)
+	<-done
+	assert.Nil(t, app.Shutdown(context.Background()))
+}
+
+type HookedConsumer struct {
+	Hooked func() *HookedService
+}
+
+type HookedService struct {
+	Service
+	App   *Application
+	Store func() *LazyStore
+	Name  string
+}
+
+type LazyStore struct {
+	Repository
+	Table string
+}
+
+func (s *HookedService) PostInit() error {
+	table := s.Store().Table
+	var store *LazyStore
+	s.App.MustGet(&store)
+	s.Name = table + store.Table
+	return nil
+}
+
+func (s *LazyStore) PostInit() error {
+	s.Table = "users"
+	return nil
+}
+
+func TestProviderInPostInit(t *testing.T) {
+	consumer := new(HookedConsumer)
+	_, err := CreateApplication(new(OptionalRoot)).Register(consumer, "").Build()
+	assert.Nil(t, err)
+
+	result := make(chan *HookedService)
+	go func() {
+		result <- consumer.Hooked()
+	}()
+	select {
+	case service := <-result:
+		assert.Equal(t, "usersusers", service.Name)
+	case <-time.After(time.Second):
+		t.Fatal("provider called in PostInit deadlocks")
+	}
+}
@@ -1,6 +1,126 @@
 package rady
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
 const (
-	PreInit  = "PreInit"
+	// PreInit is the name of hook `func()`, called when fields of a bean are injected, or when factory of it is called
+	PreInit = "PreInit"
+
+	// PostInit is the name of hook `func() error`, called when all beans are initialized, a returned error aborts startup
 	PostInit = "PostInit"
+
+	// PreDestroy is the name of hook `func()`, called in reverse dependency order when app shutdown
+	PreDestroy = "PreDestroy"
+
+	// Close is the name of hook `func() error`, called after PreDestroy
+	Close = "Close"
+
+	// DefaultShutdownTimeout is used when `rady.server.shutdown-timeout` is invalid
+	DefaultShutdownTimeout = 10 * time.Second
 )
+
+// preInit call PreInit of normal beans, PreInit of beans returned by factories is called in CallFactory
+func (a *Application) preInit() {
+	for _, bean := range a.dependencyOrder() {
+		if bean.Factory == nil {
+			a.callPreInit(bean)
+		}
+	}
+}
+
+func (a *Application) callPreInit(bean *Bean) {
+	if method := bean.Value.Addr().MethodByName(PreInit); method.IsValid() {
+		if hook, ok := method.Interface().(func()); ok {
+			a.Logger.Debug("%s [%s][%s]", PreInit, bean.Type, bean.Name)
+			hook()
+		}
+	}
+}
+
+// postInit call PostInit of all beans with BeanPostProcessor around, stop when one of them failed
+func (a *Application) postInit() {
+	order := a.dependencyOrder()
+	processors := a.processors()
+	for _, bean := range order {
+		if err := a.initBean(bean, processors, order); err != nil {
+			a.addError(err)
+			return
+		}
+	}
+}
+
+func (a *Application) callPostInit(bean *Bean) *BeanError {
+	if method := bean.Value.Addr().MethodByName(PostInit); method.IsValid() {
+		if hook, ok := method.Interface().(func() error); ok {
+			a.Logger.Debug("%s [%s][%s]", PostInit, bean.Type, bean.Name)
+			if err := hook(); err != nil {
+				beanErr := NewBeanError(HookFailed, bean.Type, bean.Name, "", fmt.Sprintf("%s failed: %s", PostInit, err))
+				beanErr.Err = err
+				return beanErr
+			}
+		}
+	}
+	return nil
+}
+
+// initBeans call factories and PreInit of beans in order, then PostInit of them with BeanPostProcessor around, stop when one of them failed
+func (a *Application) initBeans(beans, processors []*Bean) *BeanError {
+	for _, bean := range beans {
+		if method := bean.Factory; method != nil {
+			if err := method.Call(a); err != nil {
+				return NewFactoryError(bean, err)
+			}
+		}
+		a.callPreInit(bean)
+	}
+	for _, bean := range beans {
+		if err := a.initBean(bean, processors, beans); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destroy call PreDestroy and Close of beans in reverse dependency order, return errors of Close joined
+func (a *Application) destroy(ctx context.Context) error {
+	a.lazyLock.Lock()
+	order := a.dependencyOrder()
+	a.lazyLock.Unlock()
+	return a.destroyBeans(ctx, order)
+}
+
+// destroyBeans call PreDestroy and Close of beans in reverse order, errors of Close and ctx are joined
+func (a *Application) destroyBeans(ctx context.Context, order []*Bean) error {
+	errs := make([]error, 0)
+	for i := len(order) - 1; i >= 0; i-- {
+		bean := order[i]
+		if ctx.Err() != nil {
+			a.Logger.Error("Destroy [%s][%s] skipped: %s", bean.Type, bean.Name, ctx.Err())
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		if method := bean.Value.Addr().MethodByName(PreDestroy); method.IsValid() {
+			if hook, ok := method.Interface().(func()); ok {
+				a.Logger.Debug("%s [%s][%s]", PreDestroy, bean.Type, bean.Name)
+				hook()
+			}
+		}
+
+		if method := bean.Value.Addr().MethodByName(Close); method.IsValid() {
+			if hook, ok := method.Interface().(func() error); ok {
+				a.Logger.Debug("%s [%s][%s]", Close, bean.Type, bean.Name)
+				if closeErr := hook(); closeErr != nil {
+					a.Logger.Error("%s of [%s][%s] failed: %s", Close, bean.Type, bean.Name, closeErr)
+					errs = append(errs, closeErr)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
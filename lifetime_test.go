@@ -0,0 +1,176 @@
+package rady
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var LifetimeSteps = make([]string, 0)
+
+type (
+	LifetimeRoot struct {
+		*LifetimeConfig
+	}
+
+	LifetimeConfig struct {
+		Configuration
+		Repo *LifetimeRepository
+	}
+
+	LifetimeRepository struct {
+		Repository
+	}
+
+	LifetimeService struct {
+		Service
+		Repo *LifetimeRepository
+	}
+
+	LifetimeClient struct {
+		Component
+		Repo *LifetimeRepository
+	}
+
+	LifetimeTest struct {
+		Testing
+		*LifetimeService
+		*LifetimeClient
+	}
+)
+
+func (c *LifetimeConfig) GetLifetimeClient(repo *LifetimeRepository) *LifetimeClient {
+	LifetimeSteps = append(LifetimeSteps, "GetLifetimeClient")
+	return &LifetimeClient{Repo: repo}
+}
+
+func (r *LifetimeRepository) PreInit() {
+	LifetimeSteps = append(LifetimeSteps, "LifetimeRepository.PreInit")
+}
+
+func (r *LifetimeRepository) PostInit() error {
+	LifetimeSteps = append(LifetimeSteps, "LifetimeRepository.PostInit")
+	return nil
+}
+
+func (s *LifetimeService) PreInit() {
+	if s.Repo != nil {
+		LifetimeSteps = append(LifetimeSteps, "LifetimeService.PreInit")
+	}
+}
+
+func (s *LifetimeService) PostInit() error {
+	LifetimeSteps = append(LifetimeSteps, "LifetimeService.PostInit")
+	return nil
+}
+
+func (c *LifetimeClient) PreInit() {
+	if c.Repo != nil {
+		LifetimeSteps = append(LifetimeSteps, "LifetimeClient.PreInit")
+	}
+}
+
+func (c *LifetimeClient) PostInit() error {
+	LifetimeSteps = append(LifetimeSteps, "LifetimeClient.PostInit")
+	return nil
+}
+
+func (l *LifetimeTest) TestLifetime(t *testing.T) {
+	assert.Equal(t, []string{
+		"LifetimeRepository.PreInit",
+		"LifetimeService.PreInit",
+		"GetLifetimeClient",
+		"LifetimeClient.PreInit",
+		"LifetimeRepository.PostInit",
+		"LifetimeClient.PostInit",
+		"LifetimeService.PostInit",
+	}, LifetimeSteps)
+}
+
+func TestLifetime(t *testing.T) {
+	LifetimeSteps = make([]string, 0)
+	CreateTest(new(LifetimeRoot)).AddTest(new(LifetimeTest)).Test(t)
+}
+
+var DestroySteps = make([]string, 0)
+
+type (
+	DestroyRoot struct {
+		*DestroyConfig
+	}
+
+	DestroyConfig struct {
+		Configuration
+		Repo    *DestroyRepository
+		Service *DestroyService
+	}
+
+	DestroyRepository struct {
+		Repository
+	}
+
+	DestroyService struct {
+		Service
+		Repo *DestroyRepository
+	}
+
+	DestroyClient struct {
+		Component
+		Service *DestroyService
+	}
+)
+
+func (c *DestroyConfig) GetDestroyClient(service *DestroyService) *DestroyClient {
+	return &DestroyClient{Service: service}
+}
+
+func (r *DestroyRepository) PreDestroy() {
+	DestroySteps = append(DestroySteps, "DestroyRepository.PreDestroy")
+}
+
+func (r *DestroyRepository) Close() error {
+	DestroySteps = append(DestroySteps, "DestroyRepository.Close")
+	return errors.New("repository closed")
+}
+
+func (s *DestroyService) PreDestroy() {
+	DestroySteps = append(DestroySteps, "DestroyService.PreDestroy")
+}
+
+func (c *DestroyClient) Close() error {
+	DestroySteps = append(DestroySteps, "DestroyClient.Close")
+	return errors.New("client closed")
+}
+
+func TestShutdown(t *testing.T) {
+	app := CreateTest(new(DestroyRoot)).Test(t)
+	DestroySteps = make([]string, 0)
+	err := app.Shutdown(context.Background())
+	assert.EqualError(t, err, "client closed\nrepository closed")
+	assert.Equal(t, []string{
+		"DestroyClient.Close",
+		"DestroyService.PreDestroy",
+		"DestroyRepository.PreDestroy",
+		"DestroyRepository.Close",
+	}, DestroySteps)
+
+	assert.Equal(t, err, app.Shutdown(context.Background()))
+	assert.Len(t, DestroySteps, 4)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	app := CreateTest(new(DestroyRoot)).Test(t)
+	assert.Equal(t, DefaultShutdownTimeout, app.GetShutdownTimeout())
+	*app.ShutdownTimeout = "1s500ms"
+	assert.Equal(t, 1500*time.Millisecond, app.GetShutdownTimeout())
+	*app.ShutdownTimeout = "soon"
+	assert.Equal(t, DefaultShutdownTimeout, app.GetShutdownTimeout())
+
+	DestroySteps = make([]string, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, errors.Is(app.Shutdown(ctx), context.Canceled))
+	assert.Empty(t, DestroySteps)
+}
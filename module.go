@@ -0,0 +1,124 @@
+package rady
+
+import (
+	"encoding/json"
+	"github.com/tidwall/gjson"
+	"reflect"
+	"strings"
+)
+
+/*
+Module is a tag to mark a struct as a Module, a reusable bundle of configurations, entities, routers, controllers, middleware and modules
+
+routes in module are prefixed by `prefix`, and value keys start with '.' are relative to `namespace`,
+tags on the module field override tags on Module, and namespace of nested module is joined with its parent
+
+Usage:
+
+	type AuthModule struct {
+		Module `prefix:"/auth" namespace:"auth"`
+		*AuthConfig
+		*AuthController
+	}
+
+	type AuthController struct {
+		Controller
+		Secret *string `value:".jwt.secret"` // auth.jwt.secret
+	}
+
+	type Root struct {
+		*AuthModule `prefix:"/admin"`
+	}
+
+beans recursively loaded by beans in module are not in the namespace
+*/
+type Module struct {
+}
+
+// MODULE is a tag to mark a field as a Module
+const MODULE = "module"
+
+/*
+ModuleDefaults can be implemented by a module, to provide default values of keys in its namespace
+
+defaults are used when keys don't exist in config file, they take precedence over `default` tags
+
+Usage:
+
+	func (m *AuthModule) Defaults() map[string]interface{} {
+		return map[string]interface{}{
+			"jwt.expire": "24h",
+		}
+	}
+*/
+type ModuleDefaults interface {
+	Defaults() map[string]interface{}
+}
+
+// CheckModule return true when type in its tag is MODULE or ContainsField(field.Type.Elem(), Module{})
+func CheckModule(field reflect.StructField) bool {
+	return CheckPtrOfStruct(field.Type) && (field.Tag.Get("type") == "" && ContainsField(field.Type.Elem(), Module{}) || field.Tag.Get("type") == MODULE)
+}
+
+// GetNamespace return `namespace` in tag of the field, or in tag of Module in it
+func GetNamespace(field reflect.StructField) string {
+	if namespace, ok := field.Tag.Lookup("namespace"); ok {
+		return strings.Trim(namespace, " .")
+	}
+	for i := 0; i < field.Type.Elem().NumField(); i++ {
+		child := field.Type.Elem().Field(i)
+		if child.Type == reflect.TypeOf(Module{}) {
+			return strings.Trim(child.Tag.Get("namespace"), " .")
+		}
+	}
+	return ""
+}
+
+// JoinNamespace join namespace of nested module with its parent, like `auth.jwt`
+func JoinNamespace(parent, namespace string) string {
+	if parent == "" || namespace == "" {
+		return parent + namespace
+	}
+	return parent + "." + namespace
+}
+
+// ResolveKey return key in namespace when it starts with '.', like `.secret` in namespace `auth` is `auth.secret`
+func ResolveKey(namespace, key string) string {
+	if !strings.HasPrefix(key, ".") {
+		return key
+	}
+	return JoinNamespace(namespace, key[1:])
+}
+
+// loadModule load fields of a module like fields of Root, beans loaded are in namespace of the module
+func (a *Application) loadModule(field reflect.StructField, prefix string) {
+	parent := a.namespace
+	a.namespace = JoinNamespace(parent, GetNamespace(field))
+	defer func() {
+		a.namespace = parent
+	}()
+	prefix = GetNewPrefix(prefix, GetPathFromType(field, Module{}))
+	a.Logger.Debug("Load Module: %s (namespace: %s, prefix: %s)", field.Type, a.namespace, prefix)
+
+	moduleValue := reflect.New(field.Type.Elem())
+	if module, ok := moduleValue.Interface().(ModuleDefaults); ok {
+		a.loadDefaults(module.Defaults())
+	}
+	for i := 0; i < field.Type.Elem().NumField(); i++ {
+		a.loadPrimeField(field.Type.Elem().Field(i), prefix)
+	}
+}
+
+// loadDefaults set values in defaults into Defaults, keys are in current namespace
+func (a *Application) loadDefaults(defaults map[string]interface{}) {
+	for key, value := range defaults {
+		fullKey := ResolveKey(a.namespace, "."+key)
+		data, err := json.Marshal(value)
+		if err != nil {
+			a.addError(NewBeanError(UnknownValueType, reflect.TypeOf(value), fullKey, "", "default value cannot be encoded as json"))
+			continue
+		}
+		a.Defaults[fullKey] = gjson.ParseBytes(data)
+		a.Logger.Debug("Default Value '%s': %s", fullKey, data)
+	}
+}
@@ -0,0 +1,104 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type (
+	ModuleRoot struct {
+		*AuthModule `prefix:"/admin"`
+	}
+
+	AuthModule struct {
+		Module `prefix:"/auth" namespace:"rady"`
+		*AuthConfig
+		*AuthController
+		*TokenModule
+	}
+
+	TokenModule struct {
+		Module `namespace:"jwt"`
+		*TokenController
+	}
+
+	AuthConfig struct {
+		Configuration
+	}
+
+	AuthParam struct {
+		Parameter
+		Port *int64 `value:".redis.port"`
+	}
+
+	AuthClient struct {
+		Component
+		Port int64
+	}
+
+	AuthController struct {
+		Controller `prefix:"/users"`
+		Host       *string `value:".redis.host"`
+		Expire     *string `value:".expire"`
+		Client     *AuthClient
+	}
+
+	TokenController struct {
+		Controller `prefix:"/tokens"`
+		Start      *string `value:".start"`
+		Secret     *string `value:".secret" default:"changeit"`
+	}
+)
+
+func (m *AuthModule) Defaults() map[string]interface{} {
+	return map[string]interface{}{
+		"expire": "24h",
+	}
+}
+
+func (m *TokenModule) Defaults() map[string]interface{} {
+	return map[string]interface{}{
+		"secret": "rady",
+	}
+}
+
+func (c *AuthConfig) GetAuthClient(param *AuthParam) *AuthClient {
+	return &AuthClient{Port: *param.Port}
+}
+
+func (a *AuthController) Get(ctx Context) error {
+	return ctx.String(http.StatusOK, *a.Host)
+}
+
+func (t *TokenController) Get(ctx Context) error {
+	return ctx.String(http.StatusOK, *t.Secret)
+}
+
+func TestModule(t *testing.T) {
+	app, err := CreateApplication(new(ModuleRoot)).Build()
+	assert.Nil(t, err)
+
+	for path, body := range map[string]string{
+		"/admin/users":  "127.0.0.1",
+		"/admin/tokens": "rady",
+	} {
+		rec := httptest.NewRecorder()
+		app.Server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, body, rec.Body.String())
+	}
+
+	ctrl := app.BeanMap[reflect.TypeOf(new(AuthController))]["*rady.AuthController"]
+	assert.Equal(t, "rady", ctrl.Namespace)
+	auth := ctrl.Value.Addr().Interface().(*AuthController)
+	assert.Equal(t, "24h", *auth.Expire)
+	assert.Equal(t, int64(6937), auth.Client.Port)
+
+	token := app.BeanMap[reflect.TypeOf(new(TokenController))]["*rady.TokenController"]
+	assert.Equal(t, "rady.jwt", token.Namespace)
+	assert.Equal(t, "2018-01-30T00:00:00Z", *token.Value.Addr().Interface().(*TokenController).Start)
+	assert.Contains(t, app.ValueBeanMap, "rady.jwt.secret")
+}
@@ -0,0 +1,191 @@
+package rady
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PlaceholderStart is the start of placeholder in string values of config file
+	PlaceholderStart = "${"
+
+	// PlaceholderEnd is the end of placeholder in string values of config file
+	PlaceholderEnd = "}"
+
+	// EnvPlaceholder is the prefix of placeholder reading env, like `${env:HOME}`
+	EnvPlaceholder = "env:"
+)
+
+/*
+placeholders resolves placeholders in string values of config file
+
+	${rady.db.host}: value of the key, overridden by env and Args like values, see lookupValue
+
+	${rady.db.host:localhost}: default is used when the key doesn't exist
+
+	${env:HOME} and ${env:HOME:/root}: value of the environment variable
+
+placeholders in values referred are resolved recursively, and circular references are errors
+*/
+type placeholders struct {
+	app       *Application
+	root      interface{}
+	resolving []string
+}
+
+// interpolate resolve placeholders in config, config is returned as it is when there is none
+func (a *Application) interpolate(config string) (string, error) {
+	if !strings.Contains(config, PlaceholderStart) {
+		return config, nil
+	}
+	root, err := decodeJSON(config)
+	if err != nil {
+		return "", err
+	}
+	p := &placeholders{app: a, root: root}
+	if err := p.resolveTree(root, ""); err != nil {
+		return "", err
+	}
+	result, err := json.Marshal(root)
+	return string(result), err
+}
+
+// resolveTree resolve string values in objects and arrays of node, prefix is the key of node
+func (p *placeholders) resolveTree(node interface{}, prefix string) (err error) {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if value[key], err = p.resolveNode(JoinNamespace(prefix, key), child); err != nil {
+				return
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			if value[i], err = p.resolveNode(JoinNamespace(prefix, strconv.Itoa(i)), child); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// resolveNode return node with placeholders resolved
+func (p *placeholders) resolveNode(key string, node interface{}) (interface{}, error) {
+	if text, ok := node.(string); ok {
+		return p.resolveString(key, text)
+	}
+	return node, p.resolveTree(node, key)
+}
+
+// resolveString replace placeholders in text, the value of key
+func (p *placeholders) resolveString(key, text string) (string, error) {
+	if !strings.Contains(text, PlaceholderStart) {
+		return text, nil
+	}
+	for _, resolving := range p.resolving {
+		if resolving == key {
+			return "", fmt.Errorf("circular placeholder: %s -> %s", strings.Join(p.resolving, " -> "), key)
+		}
+	}
+	p.resolving = append(p.resolving, key)
+	defer func() {
+		p.resolving = p.resolving[:len(p.resolving)-1]
+	}()
+
+	var result strings.Builder
+	for {
+		start := strings.Index(text, PlaceholderStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start:], PlaceholderEnd)
+		if end < 0 {
+			return "", fmt.Errorf("placeholder in '%s' of %s is not closed", text, key)
+		}
+		value, err := p.resolvePlaceholder(text[start+len(PlaceholderStart) : start+end])
+		if err != nil {
+			return "", fmt.Errorf("%s of %s", err, key)
+		}
+		result.WriteString(text[:start])
+		result.WriteString(value)
+		text = text[start+end+len(PlaceholderEnd):]
+	}
+	result.WriteString(text)
+	return result.String(), nil
+}
+
+// resolvePlaceholder return value of expression in placeholder, like `rady.db.host:localhost` or `env:HOME`
+func (p *placeholders) resolvePlaceholder(expression string) (string, error) {
+	if strings.HasPrefix(expression, EnvPlaceholder) {
+		name, defaultValue, hasDefault := splitPlaceholder(strings.TrimPrefix(expression, EnvPlaceholder))
+		if value := os.Getenv(name); value != "" {
+			return value, nil
+		}
+		if hasDefault {
+			return defaultValue, nil
+		}
+		return "", fmt.Errorf("env %s in placeholder is not set", name)
+	}
+
+	key, defaultValue, hasDefault := splitPlaceholder(expression)
+	if value, ok := GetEnvValue(GetEnvName(key)); ok {
+		return value.String(), nil
+	}
+	if value, ok := p.app.Overrides[key]; ok {
+		return value.String(), nil
+	}
+	node, ok := findNode(p.root, key)
+	if !ok {
+		if hasDefault {
+			return defaultValue, nil
+		}
+		return "", fmt.Errorf("key %s in placeholder doesn't exist", key)
+	}
+	switch value := node.(type) {
+	case string:
+		return p.resolveString(key, value)
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(value)
+		return string(data), err
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+// splitPlaceholder split expression into name and default after the first colon
+func splitPlaceholder(expression string) (string, string, bool) {
+	pair := strings.SplitN(expression, ":", 2)
+	if len(pair) == 2 {
+		return strings.Trim(pair[0], " "), pair[1], true
+	}
+	return strings.Trim(pair[0], " "), "", false
+}
+
+// findNode find node of key in root, parts of key are keys of objects or indexes of arrays
+func findNode(root interface{}, key string) (interface{}, bool) {
+	node := root
+	for _, part := range strings.Split(key, ".") {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			child, ok := value[part]
+			if !ok {
+				return nil, false
+			}
+			node = child
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			node = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
@@ -0,0 +1,87 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	os.Setenv("RADY_TEST_HOME", "/home/rady")
+	defer ResetEnv("RADY_TEST_HOME")
+	app := CreateApplication(new(ConfigTestDefault))
+
+	config, err := app.interpolate(`{"rady": {
+		"db": {"user": "root", "port": 5432, "url": "postgres://${rady.db.user}@${rady.db.host:localhost}:${rady.db.port}/app"},
+		"home": "${env:RADY_TEST_HOME}/${rady.name}",
+		"name": "${env:RADY_TEST_NAME:rady}",
+		"hosts": ["${rady.db.host:localhost}", "${rady.hosts.0}.local"],
+		"ports": [80, 443],
+		"raw": "${rady.ports}"
+	}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "postgres://root@localhost:5432/app", gjson.Get(config, "rady.db.url").String())
+	assert.Equal(t, "/home/rady/rady", gjson.Get(config, "rady.home").String())
+	assert.Equal(t, "localhost.local", gjson.Get(config, "rady.hosts.1").String())
+	assert.Equal(t, "[80,443]", gjson.Get(config, "rady.raw").String())
+	assert.Equal(t, int64(5432), gjson.Get(config, "rady.db.port").Int())
+
+	os.Setenv("RADY_DB_USER", "admin")
+	defer ResetEnv("RADY_DB_USER")
+	config, err = app.interpolate(`{"rady": {"db": {"user": "root", "url": "postgres://${rady.db.user}@localhost/app"}}}`)
+	assert.Nil(t, err)
+	assert.Equal(t, "postgres://admin@localhost/app", gjson.Get(config, "rady.db.url").String())
+
+	raw := `{"rady": {"name": "rady"}}`
+	config, err = app.interpolate(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, raw, config)
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	app := CreateApplication(new(ConfigTestDefault))
+	testSets := map[string]string{
+		`{"a": "${b}", "b": "${c}", "c": "${a}"}`: "circular placeholder",
+		`{"a": "${b}"}`:                   "key b in placeholder doesn't exist",
+		`{"a": "${env:RADY_TEST_UNSET}"}`: "env RADY_TEST_UNSET in placeholder is not set",
+		`{"a": "${b"}`:                    "is not closed",
+	}
+	for config, message := range testSets {
+		_, err := app.interpolate(config)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+type PlaceholderRoot struct {
+	CONF `path:"./resources/placeholder.yaml"`
+	*PlaceholderController
+}
+
+type PlaceholderController struct {
+	Controller
+	URL *string `value:"rady.db.url"`
+}
+
+func TestPlaceholderReload(t *testing.T) {
+	assert.Nil(t, ioutil.WriteFile("./resources/placeholder.yaml", []byte("rady:\n  db:\n    host: db\n    url: postgres://${rady.db.host}/app\n"), 0644))
+	defer os.Remove("./resources/placeholder.yaml")
+	app, err := CreateApplication(new(PlaceholderRoot)).Build()
+	assert.Nil(t, err)
+	ctrl := app.BeanMap[reflect.TypeOf(new(PlaceholderController))]["*rady.PlaceholderController"].Value.Addr().Interface().(*PlaceholderController)
+	assert.Equal(t, "postgres://db/app", *ctrl.URL)
+
+	overridden := CreateApplication(new(PlaceholderRoot)).Args([]string{"--set", "rady.db.host=pg"})
+	assert.Equal(t, "postgres://pg/app", gjson.Get(overridden.ConfigFile, "rady.db.url").String())
+
+	assert.Nil(t, ioutil.WriteFile("./resources/placeholder.yaml", []byte("rady:\n  db:\n    url: postgres://${rady.db.url}/app\n"), 0644))
+	assert.NotNil(t, app.ReloadValues())
+	assert.Equal(t, "postgres://db/app", *ctrl.URL)
+
+	assert.Nil(t, ioutil.WriteFile("./resources/placeholder.yaml", []byte("rady:\n  db:\n    host: pg\n    url: postgres://${rady.db.host}/app\n"), 0644))
+	assert.Nil(t, app.ReloadValues())
+	assert.Equal(t, "postgres://pg/app", *ctrl.URL)
+}
@@ -0,0 +1,143 @@
+package rady
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/*
+BeanPostProcessor can be implemented by any singleton bean, to process every other bean when it is initialized
+
+Before is called before PostInit of the bean, a returned error aborts startup,
+and After is called after PostInit of the bean, with the bean or the value returned by former processors,
+a returned value different from the bean replaces it
+
+	1. value with the same type of the bean is copied into the bean, so all injection points see it
+
+	2. value with other type is a proxy of the bean, injected into all Interface injection points it implements
+
+processors are called in order of `order` tag, see SortByOrder
+
+Usage:
+
+	type MetricsProcessor struct {
+		Component `order:"1"`
+	}
+
+	func (m *MetricsProcessor) Before(name string, bean interface{}) error {
+		return nil
+	}
+
+	func (m *MetricsProcessor) After(name string, bean interface{}) (interface{}, error) {
+		if service, ok := bean.(UserService); ok {
+			return &TimedUserService{service}, nil
+		}
+		return bean, nil
+	}
+*/
+type BeanPostProcessor interface {
+	Before(name string, bean interface{}) error
+	After(name string, bean interface{}) (interface{}, error)
+}
+
+// BeanPostProcessorType is the type of BeanPostProcessor
+var BeanPostProcessorType = reflect.TypeOf((*BeanPostProcessor)(nil)).Elem()
+
+// IsProcessor return true when the bean implements BeanPostProcessor
+func IsProcessor(bean *Bean) bool {
+	return bean.Type.Implements(BeanPostProcessorType)
+}
+
+// processors return singleton beans implement BeanPostProcessor or Interceptor, sorted by SortByOrder
+func (a *Application) processors() []*Bean {
+	processors := make([]*Bean, 0)
+	for _, bean := range a.sortedBeans() {
+		if !IsScoped(bean) && (IsProcessor(bean) || IsInterceptor(bean)) {
+			processors = append(processors, bean)
+		}
+	}
+	SortByOrder(processors)
+	return processors
+}
+
+/*
+initBean call PostInit of the bean, with Before and After of processors around, then intercept the bean
+
+mothers are beans may have the bean injected, proxy of the bean is injected into them
+*/
+func (a *Application) initBean(bean *Bean, processors, mothers []*Bean) *BeanError {
+	if IsProcessor(bean) {
+		return a.callPostInit(bean)
+	}
+
+	interceptors := make([]*Bean, 0)
+	postProcessors := make([]*Bean, 0)
+	for _, processor := range processors {
+		if IsInterceptor(processor) {
+			interceptors = append(interceptors, processor)
+		}
+		if IsProcessor(processor) {
+			postProcessors = append(postProcessors, processor)
+		}
+	}
+
+	for _, processor := range postProcessors {
+		if err := processor.Value.Addr().Interface().(BeanPostProcessor).Before(bean.Name, bean.Value.Addr().Interface()); err != nil {
+			return a.newProcessorError(bean, processor, "Before", err)
+		}
+	}
+
+	if err := a.callPostInit(bean); err != nil {
+		return err
+	}
+
+	current := bean.Value.Addr().Interface()
+	for _, processor := range postProcessors {
+		result, err := processor.Value.Addr().Interface().(BeanPostProcessor).After(bean.Name, current)
+		if err != nil {
+			return a.newProcessorError(bean, processor, "After", err)
+		}
+		if result != nil {
+			current = result
+		}
+	}
+	a.replaceBean(bean, reflect.ValueOf(current), mothers)
+	a.intercept(bean, interceptors, mothers)
+	return nil
+}
+
+func (a *Application) newProcessorError(bean, processor *Bean, hook string, err error) *BeanError {
+	beanErr := NewBeanError(HookFailed, bean.Type, bean.Name, "", fmt.Sprintf("%s of [%s][%s] failed: %s", hook, processor.Type, processor.Name, err))
+	beanErr.Err = err
+	return beanErr
+}
+
+// replaceBean replace the bean with value returned by processors, see BeanPostProcessor
+func (a *Application) replaceBean(bean *Bean, value reflect.Value, mothers []*Bean) {
+	if value.Type() == bean.Type {
+		if value.Pointer() != bean.Value.Addr().Pointer() && !value.IsNil() {
+			bean.Value.Set(value.Elem())
+			a.Logger.Debug("Replace [%s][%s]", bean.Type, bean.Name)
+		}
+		return
+	}
+
+	a.Logger.Debug("Proxy [%s][%s] with %s", bean.Type, bean.Name, value.Type())
+	a.injectProxy(bean, value, mothers)
+}
+
+// injectProxy append proxy to Proxies of the bean, and inject it into Interface injection points of mothers it implements
+func (a *Application) injectProxy(bean *Bean, proxy reflect.Value, mothers []*Bean) {
+	bean.Proxies = append(bean.Proxies, proxy)
+	for _, mother := range mothers {
+		for _, dep := range mother.Deps {
+			if dep.Bean != bean || !dep.Field.IsValid() {
+				continue
+			}
+			if fieldType := dep.FieldType(); fieldType.Kind() == reflect.Interface && proxy.Type().AssignableTo(fieldType) {
+				dep.Inject(proxy)
+				a.Logger.Debug("%s of [%s][%s] set proxy of [%s][%s]", dep.Path, mother.Type, mother.Name, bean.Type, bean.Name)
+			}
+		}
+	}
+}
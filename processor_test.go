@@ -0,0 +1,134 @@
+package rady
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var ProcessedNames = make([]string, 0)
+
+type (
+	ProcessorRoot struct {
+		*ProcessorConfig
+	}
+
+	ProcessorConfig struct {
+		Configuration
+	}
+
+	ShoutProcessor struct {
+		Component `order:"2"`
+	}
+
+	CounterProcessor struct {
+		Component `order:"1"`
+	}
+
+	ProcessedGreeter struct {
+		Component
+		Counter *ProcessedCounter
+	}
+
+	ProcessedCounter struct {
+		Component
+		Count int
+	}
+
+	ShoutGreeter struct {
+		Greeter
+	}
+
+	ProcessorTest struct {
+		Testing
+		Greeter Greeter `type:"component"`
+		Direct  *ProcessedGreeter
+		Counter *ProcessedCounter
+	}
+
+	RejectRoot struct {
+		*RejectController
+	}
+
+	RejectProcessor struct {
+		Component
+	}
+
+	RejectController struct {
+		Controller
+		Processor *RejectProcessor
+	}
+)
+
+func (c *ProcessorConfig) GetShoutProcessor() *ShoutProcessor {
+	return new(ShoutProcessor)
+}
+
+func (c *ProcessorConfig) GetCounterProcessor() *CounterProcessor {
+	return new(CounterProcessor)
+}
+
+func (s *ShoutProcessor) Before(name string, bean interface{}) error {
+	if _, ok := bean.(*ProcessedGreeter); ok {
+		ProcessedNames = append(ProcessedNames, name)
+	}
+	return nil
+}
+
+func (s *ShoutProcessor) After(name string, bean interface{}) (interface{}, error) {
+	if greeter, ok := bean.(*ProcessedGreeter); ok {
+		return &ShoutGreeter{greeter}, nil
+	}
+	return bean, nil
+}
+
+func (c *CounterProcessor) Before(name string, bean interface{}) error {
+	return nil
+}
+
+func (c *CounterProcessor) After(name string, bean interface{}) (interface{}, error) {
+	if _, ok := bean.(*ProcessedCounter); ok {
+		return &ProcessedCounter{Count: 42}, nil
+	}
+	return nil, nil
+}
+
+func (g *ProcessedGreeter) Greet() string {
+	return "hi"
+}
+
+func (s *ShoutGreeter) Greet() string {
+	return strings.ToUpper(s.Greeter.Greet()) + "!"
+}
+
+func (r *RejectProcessor) Before(name string, bean interface{}) error {
+	if _, ok := bean.(*RejectController); ok {
+		return errors.New("controller rejected")
+	}
+	return nil
+}
+
+func (r *RejectProcessor) After(name string, bean interface{}) (interface{}, error) {
+	return bean, nil
+}
+
+func (p *ProcessorTest) TestProcessor(t *testing.T) {
+	assert.Equal(t, "HI!", p.Greeter.Greet())
+	assert.Equal(t, "hi", p.Direct.Greet())
+	assert.Equal(t, 42, p.Counter.Count)
+	assert.True(t, p.Direct.Counter == p.Counter)
+	assert.Equal(t, []string{"*rady.ProcessedGreeter"}, ProcessedNames)
+}
+
+func TestBeanPostProcessor(t *testing.T) {
+	ProcessedNames = make([]string, 0)
+	CreateTest(new(ProcessorRoot)).AddTest(new(ProcessorTest)).Test(t)
+}
+
+func TestBeanPostProcessorError(t *testing.T) {
+	_, err := CreateApplication(new(RejectRoot)).Build()
+	hookFailed := err.(BootErrors).OfKind(HookFailed)
+	assert.Len(t, hookFailed, 1)
+	assert.Equal(t, "Before of [*rady.RejectProcessor][*rady.RejectProcessor] failed: controller rejected", hookFailed[0].Message)
+}
@@ -0,0 +1,115 @@
+package rady
+
+import (
+	"fmt"
+	"reflect"
+)
+
+/*
+Register load value as a prime bean with name, the name of type is used when name is empty
+
+value should be Ptr of struct, it doesn't need a component tag, and its fields are injected when app is built
+
+Usage:
+
+	app := CreateApplication(new(Root))
+	app.Register(&Client{Endpoint: "localhost:6379"}, "redis")
+	app.Run()
+*/
+func (a *Application) Register(value interface{}, name string) *Application {
+	valueType := reflect.TypeOf(value)
+	if valueType == nil || !CheckPtrOfStruct(valueType) || reflect.ValueOf(value).IsNil() {
+		a.addError(NewBeanError(InvalidBean, valueType, name, "", "registered bean should be non-nil Ptr of struct"))
+		return a
+	}
+	a.Logger.Debug("Register [%s][%s]", valueType, GetBeanName(valueType, GetTagFromName(name)))
+	if a.loadPrime(valueType, reflect.ValueOf(value).Elem(), GetTagFromName(name)) {
+		a.setSource(valueType, GetTagFromName(name), SourceRegistered)
+	}
+	return a
+}
+
+/*
+RegisterFactory load result of factory as a prime bean, named by its type
+
+factory is a func like factories in configuration, it returns a Ptr of struct, or a Ptr of struct and an error,
+it can be registered after CreateTest or Prepare, its params are loaded at once
+
+Usage:
+
+	app.RegisterFactory(func(config *DBConfig) (*sql.DB, error) {
+		return sql.Open(config.Driver, config.DSN)
+	})
+*/
+func (a *Application) RegisterFactory(factory interface{}) *Application {
+	factoryType := reflect.TypeOf(factory)
+	if factoryType == nil || factoryType.Kind() != reflect.Func || !CheckFactory(factoryType) {
+		a.addError(NewBeanError(InvalidBean, factoryType, "", "", "registered factory should return Ptr of struct, or Ptr of struct and error"))
+		return a
+	}
+	if method := a.loadFactory(reflect.ValueOf(factory), factoryType.Out(0).String()); method != nil && a.prepared {
+		method.LoadIns(a)
+	}
+	return a
+}
+
+/*
+GetBean return Ptr of the bean with beanType and name, name can be empty when only one bean matches
+
+beanType is Ptr of struct or Interface, a new instance is returned every time for prototype bean
+
+beans are ready to use only after app is built
+*/
+func (a *Application) GetBean(beanType reflect.Type, name string) (interface{}, error) {
+	a.lazyLock.Lock()
+	bean, err := a.lookup(beanType, name)
+	a.lazyLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if IsScoped(bean) {
+		instance, err := a.provideScoped(bean, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		bean = instance
+	}
+	return bean.Expose(beanType).Interface(), nil
+}
+
+// lookup find the bean with beanType and name in BeanMap
+func (a *Application) lookup(beanType reflect.Type, name string) (*Bean, *BeanError) {
+	if beanType == nil {
+		return nil, NewBeanError(MissingBean, beanType, name, "", "type of bean is nil")
+	}
+	if beanType.Kind() == reflect.Interface {
+		return a.resolveInterface(nil, reflect.StructField{Type: beanType, Tag: GetTagFromName(name)}, "")
+	}
+	if name == "" {
+		return a.resolveBean(reflect.StructField{Type: beanType}, "")
+	}
+	if bean, ok := a.BeanMap[beanType][name]; ok {
+		return bean, nil
+	}
+	return nil, NewBeanError(MissingBean, beanType, name, "", "there is no bean with this name")
+}
+
+/*
+MustGet set the only bean matches type of target into it, panic when the bean cannot be found
+
+Usage:
+
+	var service *UserService
+	app.MustGet(&service)
+*/
+func (a *Application) MustGet(target interface{}) {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		panic(fmt.Sprintf("target of MustGet should be non-nil Ptr, but got %T", target))
+	}
+	bean, err := a.GetBean(targetValue.Type().Elem(), "")
+	if err != nil {
+		panic(err)
+	}
+	targetValue.Elem().Set(reflect.ValueOf(bean))
+}
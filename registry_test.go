@@ -0,0 +1,101 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+type (
+	RegistryRoot struct {
+		*RegistryConfig
+	}
+
+	RegistryConfig struct {
+		Configuration
+	}
+
+	RegistryConn struct {
+		Endpoint string
+	}
+
+	RegistryClient struct {
+		Component
+		Conn *RegistryConn `type:"component"`
+	}
+
+	RegistryService struct {
+		Service
+		Client *RegistryClient
+	}
+
+	RegistryTest struct {
+		Testing
+		Service *RegistryService
+	}
+
+	Pinger interface {
+		Ping() string
+	}
+)
+
+func (s *RegistryService) Ping() string {
+	return "pong from " + s.Client.Conn.Endpoint
+}
+
+func TestRegistry(t *testing.T) {
+	conn := &RegistryConn{Endpoint: "localhost:6379"}
+	app, err := CreateApplication(new(RegistryRoot)).
+		Register(conn, "").
+		Register(new(RegistryClient), "client").
+		RegisterFactory(func(client *RegistryClient) (*RegistryService, error) {
+			return &RegistryService{Client: client}, nil
+		}).
+		Build()
+	assert.Nil(t, err)
+
+	client, err := app.GetBean(reflect.TypeOf(new(RegistryClient)), "client")
+	assert.Nil(t, err)
+	assert.True(t, client.(*RegistryClient).Conn == conn)
+
+	var service *RegistryService
+	app.MustGet(&service)
+	assert.True(t, service.Client == client)
+
+	var pinger Pinger
+	app.MustGet(&pinger)
+	assert.Equal(t, "pong from localhost:6379", pinger.Ping())
+
+	_, err = app.GetBean(reflect.TypeOf(new(RegistryClient)), "nothing")
+	assert.Equal(t, MissingBean, err.(*BeanError).Kind)
+	assert.Panics(t, func() {
+		var store *CollectionStore
+		app.MustGet(&store)
+	})
+}
+
+func (r *RegistryTest) TestRegisteredFactory(t *testing.T) {
+	assert.Equal(t, "pong from localhost:6379", r.Service.Ping())
+}
+
+func TestRegisterAfterCreateTest(t *testing.T) {
+	CreateTest(new(RegistryRoot)).
+		Register(&RegistryConn{Endpoint: "localhost:6379"}, "").
+		RegisterFactory(func(client *RegistryClient) *RegistryService {
+			return &RegistryService{Client: client}
+		}).
+		AddTest(new(RegistryTest)).
+		Test(t)
+}
+
+func TestRegistryErrors(t *testing.T) {
+	_, err := CreateApplication(new(RegistryRoot)).
+		Register(RegistryConn{}, "").
+		Register(new(RegistryClient), "").
+		Register(new(RegistryClient), "").
+		RegisterFactory(func() string { return "" }).
+		Build()
+	bootErrors := err.(BootErrors)
+	assert.Len(t, bootErrors.OfKind(InvalidBean), 2)
+	assert.Len(t, bootErrors.OfKind(DuplicateBean), 1)
+}
@@ -1,7 +1,12 @@
 package rady
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"reflect"
 	"testing"
 )
 
@@ -91,3 +96,130 @@ func (R *RecallFactoryTest) TestRecallFactory(t *testing.T) {
 func TestReloadValues(t *testing.T) {
 	CreateTest(new(App)).AddTest(new(RecallFactoryTest)).Test(t)
 }
+
+const RollbackConfig = `{"rady": {"rollback": {"name": "%s"}}}`
+
+var (
+	RollbackLiveName *string
+	RollbackSeen     []string
+	RollbackClosed   []string
+)
+
+type (
+	RollbackRoot struct {
+		CONF `path:"./resources/rollback.json"`
+		*RollbackConfiguration
+	}
+
+	RollbackConfiguration struct {
+		Configuration
+	}
+
+	RollbackParam struct {
+		Parameter
+		Name *string `value:"rady.rollback.name"`
+	}
+
+	RollbackClient struct {
+		Component
+		Name string
+	}
+
+	RollbackGuard struct {
+		Component
+		Name string
+	}
+)
+
+func (c *RollbackConfiguration) GetRollbackClient(param *RollbackParam) *RollbackClient {
+	return &RollbackClient{Name: *param.Name}
+}
+
+func (c *RollbackConfiguration) GetRollbackGuard(param *RollbackParam, client *RollbackClient) (*RollbackGuard, error) {
+	if RollbackLiveName != nil {
+		RollbackSeen = append(RollbackSeen, *RollbackLiveName+"->"+client.Name)
+	}
+	switch *param.Name {
+	case "fail":
+		return nil, errors.New("name cannot be fail")
+	case "panic":
+		panic("name cannot be panic")
+	}
+	return &RollbackGuard{Name: client.Name}, nil
+}
+
+func (c *RollbackClient) Close() error {
+	RollbackClosed = append(RollbackClosed, c.Name)
+	return nil
+}
+
+func writeRollbackConfig(content string) error {
+	return ioutil.WriteFile("./resources/rollback.json", []byte(content), 0644)
+}
+
+func TestReloadRollback(t *testing.T) {
+	assert.Nil(t, writeRollbackConfig(fmt.Sprintf(RollbackConfig, "rady")))
+	defer os.Remove("./resources/rollback.json")
+
+	app, err := CreateApplication(new(RollbackRoot)).Build()
+	assert.Nil(t, err)
+	client := app.BeanMap[reflect.TypeOf(new(RollbackClient))]["GetRollbackClient"].Value.Addr().Interface().(*RollbackClient)
+	guard := app.BeanMap[reflect.TypeOf(new(RollbackGuard))]["GetRollbackGuard"].Value.Addr().Interface().(*RollbackGuard)
+	name := app.ValueBeanMap["rady.rollback.name"]
+	RollbackLiveName = name.ValueMap[StringType].Addr().Interface().(*string)
+	RollbackSeen, RollbackClosed = nil, nil
+	defer func() {
+		RollbackLiveName = nil
+	}()
+
+	assertRolledBack := func(err error) {
+		var reloadErr *BeanError
+		assert.True(t, errors.As(err, &reloadErr))
+		assert.Equal(t, ReloadFailed, reloadErr.Kind)
+		assert.NotNil(t, reloadErr.Err)
+		assert.Equal(t, "rady", name.Value.String())
+		assert.Equal(t, "rady", client.Name)
+		assert.Equal(t, "rady", guard.Name)
+	}
+
+	assert.Nil(t, writeRollbackConfig(`{"rady": {"rollback": `))
+	assertRolledBack(app.ReloadValues())
+
+	assert.Nil(t, writeRollbackConfig(fmt.Sprintf(RollbackConfig, "fail")))
+	err = app.ReloadValues()
+	assertRolledBack(err)
+	assert.Contains(t, err.Error(), "GetRollbackGuard")
+	assert.Equal(t, []string{"rady->fail"}, RollbackSeen)
+	assert.Equal(t, []string{"fail"}, RollbackClosed)
+
+	assert.Nil(t, writeRollbackConfig(fmt.Sprintf(RollbackConfig, "panic")))
+	err = app.ReloadValues()
+	assertRolledBack(err)
+	assert.Contains(t, err.Error(), "name cannot be panic")
+
+	assert.Nil(t, writeRollbackConfig(fmt.Sprintf(RollbackConfig, "inject")))
+	assert.Nil(t, app.ReloadValues())
+	assert.Equal(t, "inject", client.Name)
+	assert.Equal(t, "inject", guard.Name)
+	assert.Equal(t, "rady->inject", RollbackSeen[len(RollbackSeen)-1])
+	assert.Equal(t, []string{"fail", "panic"}, RollbackClosed)
+}
+
+func TestValueBeanReload(t *testing.T) {
+	assert.Nil(t, writeRollbackConfig(fmt.Sprintf(RollbackConfig, "rady")))
+	defer os.Remove("./resources/rollback.json")
+
+	app, err := CreateApplication(new(RollbackRoot)).Build()
+	assert.Nil(t, err)
+	name := app.ValueBeanMap["rady.rollback.name"]
+
+	app.ConfigFile = "  " + fmt.Sprintf(RollbackConfig, "rady")
+	app.FactoryToRecall = make(map[*Method]bool)
+	assert.False(t, name.Reload(app))
+	assert.Empty(t, app.FactoryToRecall)
+
+	app.ConfigFile = fmt.Sprintf(RollbackConfig, "inject")
+	assert.True(t, name.Reload(app))
+	assert.Equal(t, "inject", name.Value.String())
+	assert.Len(t, app.FactoryToRecall, 2)
+}
@@ -0,0 +1,242 @@
+package rady
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Scheduled is a tag to run a method of singleton bean periodically, the method should be `func()` or `func() error`
+
+	cron: run when time matches the cron expression, see ParseCron
+
+	every: run at fixed rate, parsed by time.ParseDuration
+
+	value: run at fixed rate read from the key in config file or env (see GetEnvName and `env`), `default` is used when the key doesn't exist
+
+	overlap: skip a run when the last run doesn't return, if it is "false"
+
+panics and errors of the method are logged, tasks are stopped when app shutdown
+
+Usage:
+
+	type CacheService struct {
+		Service
+		Refresh Scheduled `method:"Refresh" every:"30s"`
+		Cleanup Scheduled `method:"Cleanup" cron:"0 0/5 * * * *" overlap:"false"`
+		Sync    Scheduled `method:"Sync" value:"cache.sync-interval" default:"1m"`
+	}
+*/
+type Scheduled struct {
+}
+
+// ScheduledType is the type of Scheduled
+var ScheduledType = reflect.TypeOf(Scheduled{})
+
+/*
+Task is a method of bean run by Scheduler
+
+Next return the time of next run after the time passed in,
+and Overlap is false when a run is skipped if the last run doesn't return
+*/
+type Task struct {
+	Bean    *Bean
+	Method  string
+	Next    func(time.Time) time.Time
+	Overlap bool
+	handler reflect.Value
+	running int32
+}
+
+/*
+Scheduler runs Tasks of beans, it is started with workers when app serves and stopped when app shutdown
+
+Tasks is all tasks scheduled
+*/
+type Scheduler struct {
+	Tasks   []*Task
+	logger  *Logger
+	stop    chan struct{}
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+	started bool
+	stopped bool
+	lock    sync.Mutex
+}
+
+// NewScheduler is factory function of Scheduler
+func NewScheduler(logger *Logger) *Scheduler {
+	return &Scheduler{
+		Tasks:  make([]*Task, 0),
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+}
+
+// loadTasks parse Scheduled fields of singleton beans into Tasks, a BeanError is collected when a field is invalid
+func (a *Application) loadTasks(beans []*Bean) []*Task {
+	tasks := make([]*Task, 0)
+	for _, bean := range beans {
+		if IsScoped(bean) || !CheckPtrOfStruct(bean.Type) {
+			continue
+		}
+		for i := 0; i < bean.Type.Elem().NumField(); i++ {
+			if field := bean.Type.Elem().Field(i); field.Type == ScheduledType {
+				if task, err := a.loadTask(bean, field); err != nil {
+					a.addError(err)
+				} else {
+					tasks = append(tasks, task)
+				}
+			}
+		}
+	}
+	return tasks
+}
+
+// loadTask parse a Scheduled field of the bean
+func (a *Application) loadTask(bean *Bean, field reflect.StructField) (*Task, *BeanError) {
+	fieldPath := GetFieldPath(bean.Type, field)
+	name := strings.Trim(field.Tag.Get("method"), " ")
+	handler := bean.Value.Addr().MethodByName(name)
+	if !handler.IsValid() {
+		return nil, NewBeanError(BadSchedule, bean.Type, bean.Name, fieldPath, fmt.Sprintf("method '%s' doesn't exist", name))
+	}
+	if handlerType := handler.Type(); handlerType.NumIn() != 0 || handlerType.NumOut() > 1 || handlerType.NumOut() == 1 && handlerType.Out(0) != ErrorType {
+		return nil, NewBeanError(BadSchedule, bean.Type, bean.Name, fieldPath, fmt.Sprintf("method '%s' should be `func()` or `func() error`", name))
+	}
+
+	task := &Task{Bean: bean, Method: name, Overlap: field.Tag.Get("overlap") != "false", handler: handler}
+	if expression := strings.Trim(field.Tag.Get("cron"), " "); expression != "" {
+		schedule, err := ParseCron(expression)
+		if err != nil {
+			return nil, NewBeanError(BadSchedule, bean.Type, bean.Name, fieldPath, err.Error())
+		}
+		task.Next = schedule.Next
+		return task, nil
+	}
+
+	every := strings.Trim(field.Tag.Get("every"), " ")
+	if key := strings.Trim(field.Tag.Get("value"), " "); key != "" {
+		key = ResolveKey(bean.Namespace, key)
+		if result := a.lookupValue(key, strings.Trim(field.Tag.Get("env"), " ")); result.Exists() {
+			every = result.String()
+		} else if moduleDefault, ok := a.Defaults[key]; ok {
+			every = moduleDefault.String()
+		} else {
+			every = field.Tag.Get("default")
+		}
+	}
+	interval, err := time.ParseDuration(every)
+	if err != nil || interval <= 0 {
+		return nil, NewBeanError(BadSchedule, bean.Type, bean.Name, fieldPath, fmt.Sprintf("interval '%s' should be positive duration, or please tag `cron`", every))
+	}
+	task.Next = func(t time.Time) time.Time {
+		return t.Add(interval)
+	}
+	return task, nil
+}
+
+// Schedule add tasks, they run immediately when the scheduler is started
+func (s *Scheduler) Schedule(tasks []*Task) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.Tasks = append(s.Tasks, tasks...)
+	if s.started {
+		for _, task := range tasks {
+			s.loop(task)
+		}
+	}
+}
+
+// Start run all tasks, it does nothing when the scheduler is started or stopped
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	for _, task := range s.Tasks {
+		s.loop(task)
+	}
+}
+
+// loop run the task at every Next time until the scheduler is stopped, it should be called with lock held
+func (s *Scheduler) loop(task *Task) {
+	s.logger.Debug("Schedule %s of [%s][%s]", task.Method, task.Bean.Type, task.Bean.Name)
+	stop := s.stop
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		now := time.Now()
+		for {
+			next := task.Next(now)
+			if next.IsZero() {
+				s.logger.Warning("%s of [%s][%s] will never run again", task.Method, task.Bean.Type, task.Bean.Name)
+				return
+			}
+			timer := time.NewTimer(next.Sub(time.Now()))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				now = next
+				s.run(task)
+			}
+		}
+	}()
+}
+
+// run call the task in a new goroutine, skip it when the task cannot overlap and the last run doesn't return
+func (s *Scheduler) run(task *Task) {
+	if !atomic.CompareAndSwapInt32(&task.running, 0, 1) && !task.Overlap {
+		s.logger.Warning("Skip %s of [%s][%s]: last run doesn't return", task.Method, task.Bean.Type, task.Bean.Name)
+		return
+	}
+	atomic.StoreInt32(&task.running, 1)
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer atomic.StoreInt32(&task.running, 0)
+		defer func() {
+			if err := recover(); err != nil {
+				s.logger.Error("%s of [%s][%s] panic: %s\n%s", task.Method, task.Bean.Type, task.Bean.Name, err, debug.Stack())
+			}
+		}()
+		if err := ResultError(task.handler.Call(nil)); err != nil {
+			s.logger.Error("%s of [%s][%s] failed: %s", task.Method, task.Bean.Type, task.Bean.Name, err)
+		}
+	}()
+}
+
+// Stop stop all tasks, and wait running tasks to return until ctx is done, the scheduler cannot be started again
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.lock.Lock()
+	s.stopped = true
+	if s.started {
+		close(s.stop)
+		s.started = false
+		s.stop = make(chan struct{})
+	}
+	s.lock.Unlock()
+	s.loops.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
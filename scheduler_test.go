@@ -0,0 +1,97 @@
+package rady
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type (
+	ScheduleRoot struct {
+		*ScheduleController
+	}
+
+	ScheduleService struct {
+		Service
+		Ticker    Scheduled `method:"Tick" every:"10ms"`
+		Slow      Scheduled `method:"SlowTick" value:"rady.schedule.slow" default:"5ms" overlap:"false"`
+		Panicker  Scheduled `method:"Panic" every:"10ms"`
+		Ticks     int32
+		SlowTicks int32
+		Panics    int32
+	}
+
+	ScheduleController struct {
+		Controller
+		Service *ScheduleService
+	}
+
+	BadScheduleRoot struct {
+		*BadScheduleController
+	}
+
+	BadScheduleController struct {
+		Controller
+		Missing  Scheduled `method:"Missing" every:"1s"`
+		Cron     Scheduled `method:"Run" cron:"* * *"`
+		Interval Scheduled `method:"Run" every:"often"`
+		Param    Scheduled `method:"Params" every:"1s"`
+	}
+)
+
+func (s *ScheduleService) Tick() error {
+	atomic.AddInt32(&s.Ticks, 1)
+	return errors.New("tick failed")
+}
+
+func (s *ScheduleService) SlowTick() {
+	atomic.AddInt32(&s.SlowTicks, 1)
+	time.Sleep(30 * time.Millisecond)
+}
+
+func (s *ScheduleService) Panic() {
+	atomic.AddInt32(&s.Panics, 1)
+	panic("scheduled panic")
+}
+
+func (b *BadScheduleController) Run() {}
+
+func (b *BadScheduleController) Params(times int) {}
+
+func TestScheduler(t *testing.T) {
+	app, err := CreateApplication(new(ScheduleRoot)).Build()
+	assert.Nil(t, err)
+	assert.Len(t, app.Scheduler.Tasks, 3)
+	service := app.BeanMap[reflect.TypeOf(new(ScheduleService))]["*rady.ScheduleService"].Value.Addr().Interface().(*ScheduleService)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&service.Ticks))
+	app.Scheduler.Start()
+	time.Sleep(55 * time.Millisecond)
+	assert.Nil(t, app.Shutdown(context.Background()))
+	ticks, slowTicks, panics := atomic.LoadInt32(&service.Ticks), atomic.LoadInt32(&service.SlowTicks), atomic.LoadInt32(&service.Panics)
+	assert.True(t, ticks >= 3, "ticks: %d", ticks)
+	assert.True(t, panics >= 3, "panics: %d", panics)
+	assert.True(t, slowTicks >= 1 && slowTicks <= 3, "slow ticks: %d", slowTicks)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, ticks, atomic.LoadInt32(&service.Ticks))
+}
+
+func TestScheduleErrors(t *testing.T) {
+	_, err := CreateApplication(new(BadScheduleRoot)).Build()
+	errs := err.(BootErrors).OfKind(BadSchedule)
+	assert.Len(t, errs, 4)
+	fields := make(map[string]string)
+	for _, e := range errs {
+		fields[e.Field] = e.Message
+	}
+	assert.Equal(t, "method 'Missing' doesn't exist", fields["*rady.BadScheduleController.Missing"])
+	assert.Contains(t, fields["*rady.BadScheduleController.Cron"], "expected 5 to 6 fields")
+	assert.Contains(t, fields["*rady.BadScheduleController.Interval"], "interval 'often'")
+	assert.Contains(t, fields["*rady.BadScheduleController.Param"], "should be `func()` or `func() error`")
+}
@@ -0,0 +1,221 @@
+package rady
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+const (
+	// Singleton is the default scope, a bean is created once and shared by all injection points
+	Singleton = "singleton"
+
+	// Prototype is the scope of bean tagged `scope:"prototype"`, a new instance is created for every injection point
+	Prototype = "prototype"
+
+	// Request is the scope of bean tagged `scope:"request"`, a new instance is created for every http request
+	Request = "request"
+
+	// RequestScopeKey is the key of RequestScope in Context
+	RequestScopeKey = "rady.request-scope"
+)
+
+/*
+RequestScope contains beans instantiated in a http request
+
+Beans is the request beans, found by the bean in BeanMap they are instantiated from,
+and Instances is all beans instantiated in the request, include prototype beans, they are destroyed after the request
+*/
+type RequestScope struct {
+	Beans     map[*Bean]*Bean
+	Instances []*Bean
+	lock      sync.Mutex
+}
+
+// NewRequestScope is factory function of RequestScope
+func NewRequestScope() *RequestScope {
+	return &RequestScope{
+		Beans:     make(map[*Bean]*Bean),
+		Instances: make([]*Bean, 0),
+	}
+}
+
+// GetRequestScope return RequestScope of the request, nil when there is none
+func GetRequestScope(c Context) *RequestScope {
+	if c == nil {
+		return nil
+	}
+	scope, _ := c.Get(RequestScopeKey).(*RequestScope)
+	return scope
+}
+
+// GetScope return value of `scope` in tag of the bean or its component tag, Singleton when it is neither Prototype nor Request
+func GetScope(bean *Bean) string {
+	scope := bean.Tag.Get("scope")
+	if scope == "" {
+		scope = GetComponentTag(bean.Type, "scope")
+	}
+	if scope == Prototype || scope == Request {
+		return scope
+	}
+	return Singleton
+}
+
+// IsScoped return true when the bean is prototype or request bean
+func IsScoped(bean *Bean) bool {
+	return GetScope(bean) != Singleton
+}
+
+/*
+scopedBean return the bean to inject into mother
+
+	1. singleton bean is injected as it is
+
+	2. prototype bean is instantiated for every injection point
+
+	3. request bean is instantiated once in the request scope of mother, it cannot be injected out of request
+
+scoped beans in BeanMap are never used, beans are injected into them as they are, to check dependencies in bootstrap
+*/
+func (a *Application) scopedBean(mother, bean *Bean, fieldPath string) (*Bean, *BeanError) {
+	if mother.Template == nil && IsScoped(mother) {
+		return bean, nil
+	}
+	switch GetScope(bean) {
+	case Prototype:
+		return a.instantiate(bean, mother.Scope), nil
+	case Request:
+		if mother.Scope == nil {
+			return nil, NewBeanError(ScopeMismatch, bean.Type, bean.Name, fieldPath, "request bean cannot be injected out of request, please use provider `func(Context) T`")
+		}
+		if instance, ok := mother.Scope.Beans[bean]; ok {
+			return instance, nil
+		}
+		return a.instantiate(bean, mother.Scope), nil
+	}
+	return bean, nil
+}
+
+/*
+instantiate create an instance of a prototype or request bean, fields and factory params of it are injected
+
+the instance is not initialized, factory and hooks of it are called later
+*/
+func (a *Application) instantiate(template *Bean, scope *RequestScope) *Bean {
+	instance := NewBean(reflect.New(template.Type.Elem()).Elem(), template.Tag)
+	instance.Name = template.Name
+	instance.Type = template.Type
+	instance.Template = template
+	instance.Scope = scope
+	a.created = append(a.created, instance)
+	if scope != nil {
+		scope.Instances = append(scope.Instances, instance)
+		if GetScope(template) == Request {
+			scope.Beans[template] = instance
+		}
+	}
+	a.Logger.Debug("Instantiate [%s][%s]", instance.Type, instance.Name)
+
+	if method := template.Factory; method != nil {
+		instance.Factory = method.Clone(instance)
+		instance.Deps = template.Deps
+		a.assembleParams(instance)
+	} else {
+		a.assembleFields(instance)
+	}
+	return instance
+}
+
+// assembleParams replace scoped params of the factory with their instances
+func (a *Application) assembleParams(bean *Bean) {
+	deps := bean.Deps
+	bean.Deps = make([]*Dependency, 0, len(deps))
+	for i, dep := range deps {
+		param, err := a.scopedBean(bean, dep.Bean, dep.Path)
+		if err != nil {
+			a.addError(err)
+			bean.Deps = append(bean.Deps, dep)
+			continue
+		}
+		bean.Factory.InValues[i] = param.Value
+		bean.Deps = append(bean.Deps, NewDependency(param, dep.Path, true))
+	}
+}
+
+/*
+provideScoped instantiate and initialize a prototype or request bean for provider after bootstrap
+
+request bean is instantiated once in scope, scope is nil when provider is called out of request
+*/
+func (a *Application) provideScoped(template *Bean, scope *RequestScope, fieldPath string) (*Bean, error) {
+	if scope != nil {
+		scope.lock.Lock()
+		defer scope.lock.Unlock()
+		if instance, ok := scope.Beans[template]; ok {
+			return instance, nil
+		}
+	} else if GetScope(template) == Request {
+		return nil, NewBeanError(ScopeMismatch, template.Type, template.Name, fieldPath, "request bean can only be provided in request, please use provider `func(Context) T`")
+	}
+
+	a.lazyLock.Lock()
+	count := len(a.bootErrors)
+	a.created = make([]*Bean, 0)
+	instance := a.instantiate(template, scope)
+	created := a.created
+	a.created = nil
+	errs := a.takeErrors(count)
+	processors := a.processors()
+	a.lazyLock.Unlock()
+
+	if errs == nil {
+		if err := a.initBeans(orderBeans(created), processors); err != nil {
+			errs = BootErrors{err}
+		}
+	}
+	if errs != nil {
+		if scope != nil {
+			scope.remove(created)
+		}
+		return nil, errs
+	}
+	return instance, nil
+}
+
+// remove beans from the scope, they are not destroyed
+func (s *RequestScope) remove(beans []*Bean) {
+	removed := make(map[*Bean]bool)
+	for _, bean := range beans {
+		removed[bean] = true
+	}
+	for template, instance := range s.Beans {
+		if removed[instance] {
+			delete(s.Beans, template)
+		}
+	}
+	instances := make([]*Bean, 0, len(s.Instances))
+	for _, instance := range s.Instances {
+		if !removed[instance] {
+			instances = append(instances, instance)
+		}
+	}
+	s.Instances = instances
+}
+
+/*
+requestScope is the middleware set a new RequestScope into Context, beans in it are destroyed when the request is handled
+
+config may be reloaded while the request is handled, request beans are instantiated with either old or new values
+*/
+func (a *Application) requestScope(next HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		scope := NewRequestScope()
+		c.Set(RequestScopeKey, scope)
+		defer func() {
+			scope.lock.Lock()
+			defer scope.lock.Unlock()
+			a.destroyBeans(context.Background(), orderBeans(scope.Instances))
+		}()
+		return next(c)
+	}
+}
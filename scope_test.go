@@ -0,0 +1,169 @@
+package rady
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+var (
+	ScopeCreated = 0
+	ScopeClosed  = 0
+)
+
+type (
+	ScopeRoot struct {
+		*ScopeConfig
+		*ScopeController
+	}
+
+	ScopeConfig struct {
+		Configuration
+	}
+
+	ScopeRepository struct {
+		Repository
+	}
+
+	ScopeCounter struct {
+		Component `scope:"prototype"`
+		Repo      *ScopeRepository
+		Inited    bool
+	}
+
+	ScopeClient struct {
+		Component `scope:"prototype"`
+		Counter   *ScopeCounter
+	}
+
+	ScopeTrace struct {
+		Component `scope:"request"`
+	}
+
+	ScopeUser struct {
+		Component `scope:"request"`
+		Trace     *ScopeTrace
+		Counter   *ScopeCounter
+	}
+
+	ScopeController struct {
+		Controller
+		User    func(Context) *ScopeUser
+		Trace   func(Context) (*ScopeTrace, error)
+		Counter *ScopeCounter
+	}
+
+	ScopeTest struct {
+		Testing
+		First  *ScopeCounter
+		Second *ScopeCounter
+		Client *ScopeClient
+		Other  *ScopeClient
+	}
+
+	MismatchRoot struct {
+		*MismatchController
+	}
+
+	MismatchController struct {
+		Controller
+		User *ScopeUser
+	}
+
+	PrototypeCycleRoot struct {
+		*PrototypeCycleController
+	}
+
+	PrototypeCycleController struct {
+		Controller
+		Ping *PrototypePing
+	}
+
+	PrototypePing struct {
+		Component `scope:"prototype"`
+		Pong      *PrototypePong
+	}
+
+	PrototypePong struct {
+		Component `scope:"prototype"`
+		Ping      *PrototypePing
+	}
+)
+
+func (c *ScopeConfig) GetScopeClient(counter *ScopeCounter) *ScopeClient {
+	return &ScopeClient{Counter: counter}
+}
+
+func (c *ScopeCounter) PreInit() {
+	c.Inited = c.Repo != nil
+}
+
+func (t *ScopeTrace) PreInit() {
+	ScopeCreated++
+}
+
+func (t *ScopeTrace) Close() error {
+	ScopeClosed++
+	return nil
+}
+
+func (s *ScopeController) GetUser(ctx Context) error {
+	user := s.User(ctx)
+	trace, err := s.Trace(ctx)
+	if err != nil {
+		return err
+	}
+	if user != s.User(ctx) || user.Trace != trace {
+		return fmt.Errorf("request beans are instantiated more than once")
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (s *ScopeTest) TestPrototype(t *testing.T) {
+	assert.NotNil(t, s.First)
+	assert.True(t, s.First != s.Second)
+	assert.True(t, s.First.Repo == s.Second.Repo)
+	assert.True(t, s.First.Inited)
+	assert.True(t, s.Second.Inited)
+	assert.True(t, s.Client != s.Other)
+	assert.True(t, s.Client.Counter != s.Other.Counter)
+	assert.True(t, s.Client.Counter.Inited)
+}
+
+func TestPrototypeScope(t *testing.T) {
+	CreateTest(new(ScopeRoot)).AddTest(new(ScopeTest)).Test(t)
+}
+
+func TestRequestScope(t *testing.T) {
+	ScopeCreated, ScopeClosed = 0, 0
+	app, err := CreateApplication(new(ScopeRoot)).Build()
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		app.Server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/user", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.Equal(t, 2, ScopeCreated)
+	assert.Equal(t, 2, ScopeClosed)
+
+	ctrl := app.BeanMap[reflect.TypeOf(new(ScopeController))]["*rady.ScopeController"].Value.Addr().Interface().(*ScopeController)
+	assert.True(t, ctrl.Counter.Inited)
+	_, err = ctrl.Trace(nil)
+	assert.Equal(t, ScopeMismatch, err.(*BeanError).Kind)
+}
+
+func TestScopeErrors(t *testing.T) {
+	_, err := CreateApplication(new(MismatchRoot)).Build()
+	mismatch := err.(BootErrors).OfKind(ScopeMismatch)
+	assert.Len(t, mismatch, 1)
+	assert.Equal(t, "*rady.MismatchController.User", mismatch[0].Field)
+
+	_, err = CreateApplication(new(PrototypeCycleRoot)).Build()
+	cycles := err.(BootErrors).OfKind(CircularDependency)
+	assert.Len(t, cycles, 1)
+	assert.Contains(t, cycles[0].Message, "[*rady.PrototypePing][*rady.PrototypePing] -> *rady.PrototypePing.Pong -> [*rady.PrototypePong][*rady.PrototypePong]")
+}
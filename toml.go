@@ -0,0 +1,58 @@
+package rady
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"time"
+)
+
+// tomlLocalLayouts is layouts of local datetimes, dates and times, found by names of their locations set by toml
+var tomlLocalLayouts = map[string]string{
+	"datetime-local": "2006-01-02T15:04:05.999999999",
+	"date-local":     "2006-01-02",
+	"time-local":     "15:04:05.999999999",
+}
+
+/*
+DecodeTOML decode toml file into json by github.com/BurntSushi/toml
+
+integers are kept exactly, offset datetimes are decoded as strings in RFC 3339,
+local datetimes, dates and times as strings like `1979-05-27T07:32:00`, `1979-05-27` and `07:32:00`,
+inf and nan are rejected because json cannot encode them
+*/
+func DecodeTOML(content []byte) ([]byte, error) {
+	var data map[string]interface{}
+	if _, err := toml.Decode(string(content), &data); err != nil {
+		return nil, err
+	}
+	result, err := json.Marshal(tomlToJSON(data))
+	if err != nil {
+		return nil, fmt.Errorf("toml cannot be encoded as json: %s", err)
+	}
+	return result, nil
+}
+
+// tomlToJSON convert datetimes in value decoded from toml into strings
+func tomlToJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			value[key] = tomlToJSON(child)
+		}
+	case []map[string]interface{}:
+		for _, child := range value {
+			tomlToJSON(child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = tomlToJSON(child)
+		}
+	case time.Time:
+		if layout, ok := tomlLocalLayouts[value.Location().String()]; ok {
+			return value.Format(layout)
+		}
+		return value.Format(time.RFC3339Nano)
+	}
+	return value
+}
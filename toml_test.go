@@ -0,0 +1,90 @@
+package rady
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+	"testing"
+)
+
+const TOMLConfig = `
+# rady config
+title = "rady"
+
+[rady.server]
+addr = ":8081"  # comment
+ports = [
+  80,
+  443, # https
+]
+ready = [true, false]
+start = 2018-01-30T00:00:00Z
+local = 1979-05-27 07:32:00
+day = 1979-05-27
+id = 9007199254740993
+
+[rady.redis]
+host = '127.0.0.1'
+port = 6_937
+mask = 0xff
+ratio = 1.5e2
+"quoted.key" = "\u0072ady\tinject"
+cache.size = 1024
+
+[rady.mysql]
+dsn = """
+root@localhost\
+    /app"""
+path = '''C:\rady'''
+options = { charset = "utf8", timeout = 30, retry = { times = 3 } }
+
+[[rady.workers]]
+name = "poller"
+
+[[rady.workers]]
+name = "consumer"
+`
+
+func TestDecodeTOML(t *testing.T) {
+	data, err := DecodeTOML([]byte(TOMLConfig))
+	assert.Nil(t, err)
+	config := string(data)
+	assert.Equal(t, "rady", gjson.Get(config, "title").String())
+	assert.Equal(t, ":8081", gjson.Get(config, "rady.server.addr").String())
+	assert.Equal(t, "[80,443]", gjson.Get(config, "rady.server.ports").Raw)
+	assert.Equal(t, "[true,false]", gjson.Get(config, "rady.server.ready").Raw)
+	assert.Equal(t, 2018, gjson.Get(config, "rady.server.start").Time().Year())
+	assert.Equal(t, "1979-05-27T07:32:00", gjson.Get(config, "rady.server.local").String())
+	assert.Equal(t, "1979-05-27", gjson.Get(config, "rady.server.day").String())
+	assert.Equal(t, "9007199254740993", gjson.Get(config, "rady.server.id").Raw)
+	assert.Equal(t, "127.0.0.1", gjson.Get(config, "rady.redis.host").String())
+	assert.Equal(t, int64(6937), gjson.Get(config, "rady.redis.port").Int())
+	assert.Equal(t, int64(255), gjson.Get(config, "rady.redis.mask").Int())
+	assert.Equal(t, float64(150), gjson.Get(config, "rady.redis.ratio").Float())
+	assert.Equal(t, "rady\tinject", gjson.Get(config, `rady.redis.quoted\.key`).String())
+	assert.Equal(t, int64(1024), gjson.Get(config, "rady.redis.cache.size").Int())
+	assert.Equal(t, "root@localhost/app", gjson.Get(config, "rady.mysql.dsn").String())
+	assert.Equal(t, `C:\rady`, gjson.Get(config, "rady.mysql.path").String())
+	assert.Equal(t, "utf8", gjson.Get(config, "rady.mysql.options.charset").String())
+	assert.Equal(t, int64(3), gjson.Get(config, "rady.mysql.options.retry.times").Int())
+	assert.Equal(t, "consumer", gjson.Get(config, "rady.workers.1.name").String())
+}
+
+func TestDecodeTOMLErrors(t *testing.T) {
+	testSets := map[string]string{
+		"a = 1\na = 2":          "line 2 (last key \"a\"): Key 'a' has already been defined",
+		"[a]\n[a]":              "Key 'a' has already been defined",
+		"a = \"rady":            "unexpected EOF; expected '\"'",
+		"a = [1, 2":             "expected a comma (',') or array terminator (']')",
+		"a = 1 2":               "expected a top-level item to end with a newline",
+		"a = inf":               "cannot be encoded as json",
+		"a = \"\\x\"":           "expected two hexadecimal digits",
+		"[[a]]\nb = 1\n[a.b.c]": "Key 'a.b' was already created",
+		"a":                     "expected key separator '='",
+	}
+	for content, message := range testSets {
+		_, err := DecodeTOML([]byte(content))
+		if assert.NotNil(t, err, content) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+}
@@ -2,13 +2,20 @@ package rady
 
 import (
 	"fmt"
-	"github.com/ghodss/yaml"
 	"io/ioutil"
 	"reflect"
 	"strings"
 	"unicode"
 )
 
+var (
+	// ErrorType is the type of error interface
+	ErrorType = reflect.TypeOf((*error)(nil)).Elem()
+
+	// ContextType is the type of Context interface
+	ContextType = reflect.TypeOf((*Context)(nil)).Elem()
+)
+
 // ContainsField return true when Mother has a child as same type as filed
 func ContainsField(Mother reflect.Type, field interface{}) bool {
 	fieldType := reflect.TypeOf(field)
@@ -75,6 +82,81 @@ func CheckComponents(field reflect.StructField) bool {
 	return CheckPtrOfStruct(field.Type) && (ok || field.Tag.Get("type") == "" && ContainsFields(field.Type.Elem(), ComponentTypes))
 }
 
+// CheckInterfaceComponents return true when field is kind of Interface and type in its tag is in COMPONENTS
+func CheckInterfaceComponents(field reflect.StructField) bool {
+	_, ok := COMPONENTS[field.Tag.Get("type")]
+	return field.Type.Kind() == reflect.Interface && ok
+}
+
+/*
+CheckCollectionComponents return true when field is kind of Slice or map with string key, and
+
+	1. element is kind of Interface and type in its tag is in COMPONENTS
+
+	2. element is Ptr of struct and CheckComponents would return true for it
+*/
+func CheckCollectionComponents(field reflect.StructField) bool {
+	fieldType := field.Type
+	if fieldType.Kind() != reflect.Slice && (fieldType.Kind() != reflect.Map || fieldType.Key().Kind() != reflect.String) {
+		return false
+	}
+	elemType := fieldType.Elem()
+	_, ok := COMPONENTS[field.Tag.Get("type")]
+	if elemType.Kind() == reflect.Interface {
+		return ok
+	}
+	return CheckPtrOfStruct(elemType) && (ok || field.Tag.Get("type") == "" && ContainsFields(elemType.Elem(), ComponentTypes))
+}
+
+/*
+CheckProviderComponents return true when field is `func() T` or `func() (T, error)`, the func can take a Context as the only param
+
+and CheckComponents or CheckInterfaceComponents would return true for a field of T with the same tag
+*/
+func CheckProviderComponents(field reflect.StructField) bool {
+	fieldType := field.Type
+	if fieldType.Kind() != reflect.Func || fieldType.NumIn() > 1 || fieldType.NumIn() == 1 && fieldType.In(0) != ContextType {
+		return false
+	}
+	if fieldType.NumOut() != 1 && (fieldType.NumOut() != 2 || fieldType.Out(1) != ErrorType) {
+		return false
+	}
+	target := GetProviderTarget(field)
+	return CheckComponents(target) || CheckInterfaceComponents(target)
+}
+
+// GetProviderTarget return a field of T with the same name and tag, field should be `func() T` or `func() (T, error)`
+func GetProviderTarget(field reflect.StructField) reflect.StructField {
+	return reflect.StructField{Name: field.Name, Type: field.Type.Out(0), Tag: field.Tag}
+}
+
+// CheckFactory return true when methodType returns a Ptr of struct, or a Ptr of struct and an error
+func CheckFactory(methodType reflect.Type) bool {
+	if methodType.NumOut() != 1 && (methodType.NumOut() != 2 || methodType.Out(1) != ErrorType) {
+		return false
+	}
+	return CheckPtrOfStruct(methodType.Out(0))
+}
+
+// CheckValueParam return true when paramType is Ptr of struct with fields tagged `value`
+func CheckValueParam(paramType reflect.Type) bool {
+	if !CheckPtrOfStruct(paramType) {
+		return false
+	}
+	for i := 0; i < paramType.Elem().NumField(); i++ {
+		field := paramType.Elem().Field(i)
+		if CheckValues(field) && strings.Trim(field.Tag.Get("value"), " ") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOptional return true when field has `optional:"true"`
+func IsOptional(field reflect.StructField) bool {
+	return field.Tag.Get("optional") == "true"
+}
+
 func CheckStruct(fieldType reflect.Type) bool {
 	return fieldType.Kind() == reflect.Struct
 }
@@ -102,6 +184,16 @@ func GetBeanName(Type reflect.Type, tag reflect.StructTag) string {
 	return Type.String()
 }
 
+// GetFieldPath generate path of a field for error reporting, like `*rady.BookController.RedisComponent`
+func GetFieldPath(motherType reflect.Type, field reflect.StructField) string {
+	return fmt.Sprintf("%s.%s", motherType, field.Name)
+}
+
+// GetParamPath generate path of a factory param for error reporting, like `GetRedisComponent(#0)`
+func GetParamPath(methodName string, index int) string {
+	return fmt.Sprintf("%s(#%d)", methodName, index)
+}
+
 // GetTagFromName generate tag from name
 func GetTagFromName(name string) reflect.StructTag {
 	return (reflect.StructTag)(fmt.Sprintf(`name:"%s"`, name))
@@ -148,10 +240,8 @@ func ConfirmBeanInMap(BeanMap map[reflect.Type]map[string]*Bean, fieldType refle
 /*
 GetJSONFromAnyFile can get json string from file
 
-This function work well only when:
-
-	1. fileType == "yaml" or (fileType != "json" and path end with ".yml" or ".yaml"), and content in file is yaml
-	2. content in file is json
+the file is decoded by ConfigDecoder of fileType, or of the suffix of path when fileType is unknown,
+and content is returned as it is when both of them are unknown
 */
 func GetJSONFromAnyFile(path string, fileType string) (string, error) {
 	fileBytes, err := ioutil.ReadFile(path)
@@ -159,11 +249,15 @@ func GetJSONFromAnyFile(path string, fileType string) (string, error) {
 		return "", err
 	}
 
-	if fileType != JSON {
-		if fileType == YAML || strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml") {
-			fileBytes, err = yaml.YAMLToJSON(fileBytes)
+	decoder, ok := ConfigDecoders[fileType]
+	if !ok {
+		if suffixType, known := GetConfigType(path); known {
+			decoder, ok = ConfigDecoders[suffixType]
 		}
 	}
+	if ok {
+		fileBytes, err = decoder(fileBytes)
+	}
 	return string(fileBytes), err
 }
 
@@ -188,6 +282,20 @@ func GetPathFromType(field reflect.StructField, Type interface{}) string {
 	return ""
 }
 
+// GetComponentTag return value of key in tag of the first field in ComponentTypes, fieldType should be kind of Ptr
+func GetComponentTag(fieldType reflect.Type, key string) string {
+	if !CheckPtrOfStruct(fieldType) {
+		return ""
+	}
+	for i := 0; i < fieldType.Elem().NumField(); i++ {
+		child := fieldType.Elem().Field(i)
+		if _, ok := ComponentTypes[child.Type]; ok {
+			return child.Tag.Get(key)
+		}
+	}
+	return ""
+}
+
 func ParseHandlerName(Name string) (ok bool, method interface{}, path string) {
 	if method, ok = StrToMethod[Name]; ok {
 		return
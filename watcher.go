@@ -0,0 +1,94 @@
+package rady
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+/*
+ConfigWatcher reloads config file by ReloadValues when it or its overlays of modes are changed, it is a Runnable started with server
+
+	rady.config.watch: watch config file if it is true
+
+	rady.config.watch-interval: interval to poll the file, parsed by time.ParseDuration
+
+	rady.config.watch-debounce: the file is reloaded only when it is not changed in this duration
+*/
+type ConfigWatcher struct {
+	App      *Application
+	Watch    *bool   `value:"rady.config.watch" default:"false"`
+	Interval *string `value:"rady.config.watch-interval" default:"1s"`
+	Debounce *string `value:"rady.config.watch-debounce" default:"200ms"`
+}
+
+// GetDuration parse value, return defaultValue when it is invalid
+func GetDuration(value *string, defaultValue time.Duration) time.Duration {
+	if value != nil {
+		if duration, err := time.ParseDuration(*value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// Run poll the config file and its overlays of modes until ctx is done, it returns immediately when watch is disabled
+func (w *ConfigWatcher) Run(ctx context.Context) error {
+	if w.Watch == nil || !*w.Watch {
+		return nil
+	}
+	paths, _ := w.App.GetConfigPathsAndType()
+	interval, debounce := GetDuration(w.Interval, time.Second), GetDuration(w.Debounce, 200*time.Millisecond)
+	w.App.Logger.Info("Watch config file %v every %s", paths, interval)
+
+	content, _ := readFiles(paths)
+	var changedAt time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			current, err := readFiles(paths)
+			if err != nil {
+				w.App.Logger.Warning("Watch config file %v failed: %s", paths, err)
+				continue
+			}
+			if !bytes.Equal(current, content) {
+				content, changedAt = current, now
+				w.App.Logger.Debug("Config file %v changed", paths)
+				continue
+			}
+			if !changedAt.IsZero() && now.Sub(changedAt) >= debounce {
+				changedAt = time.Time{}
+				w.reload(paths)
+			}
+		}
+	}
+}
+
+// reload call ReloadValues, old values are kept when it fails
+func (w *ConfigWatcher) reload(paths []string) {
+	if err := w.App.ReloadValues(); err != nil {
+		w.App.Logger.Warning("Config file %v is not reloaded", paths)
+	}
+}
+
+// readFiles return contents of files with their paths, a file doesn't exist is read as empty
+func readFiles(paths []string) ([]byte, error) {
+	var buffer bytes.Buffer
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		buffer.WriteString(path)
+		buffer.WriteByte(0)
+		buffer.Write(content)
+		buffer.WriteByte(0)
+	}
+	return buffer.Bytes(), nil
+}
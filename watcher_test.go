@@ -0,0 +1,99 @@
+package rady
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const WatchedConfig = `{"rady": {"config": {"watch": true, "watch-interval": "5ms", "watch-debounce": "20ms"}, "watched": {"name": "%s"}}}`
+
+type (
+	WatcherRoot struct {
+		CONF `path:"./resources/watched.json"`
+		*WatcherConfig
+		*WatcherController
+	}
+
+	WatcherConfig struct {
+		Configuration
+	}
+
+	WatchedParam struct {
+		Parameter
+		Name *string `value:"rady.watched.name"`
+	}
+
+	WatchedClient struct {
+		Component
+		Name string
+	}
+
+	WatcherController struct {
+		Controller
+		Name     *string `value:"rady.watched.name"`
+		Client   *WatchedClient
+		Reloaded chan *ReloadedEvent
+	}
+)
+
+func (c *WatcherConfig) GetWatchedClient(param *WatchedParam) *WatchedClient {
+	return &WatchedClient{Name: *param.Name}
+}
+
+func (w *WatcherController) PreInit() {
+	w.Reloaded = make(chan *ReloadedEvent, 1)
+}
+
+func (w *WatcherController) OnReloaded(event *ReloadedEvent) {
+	w.Reloaded <- event
+}
+
+func writeWatchedConfig(name string) error {
+	return ioutil.WriteFile("./resources/watched.json", []byte(fmt.Sprintf(WatchedConfig, name)), 0644)
+}
+
+func TestConfigWatcher(t *testing.T) {
+	assert.Nil(t, writeWatchedConfig("rady"))
+	defer os.Remove("./resources/watched.json")
+
+	app, err := CreateApplication(new(WatcherRoot)).Build()
+	assert.Nil(t, err)
+	ctrl := app.BeanMap[reflect.TypeOf(new(WatcherController))]["*rady.WatcherController"].Value.Addr().Interface().(*WatcherController)
+	assert.Equal(t, "rady", *ctrl.Name)
+	assert.True(t, *app.Watcher.Watch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- app.Watcher.Run(ctx)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	request := app.Server.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	assert.Nil(t, app.requestScope(func(c Context) error {
+		assert.Equal(t, "rady", *ctrl.Name)
+		assert.Nil(t, writeWatchedConfig("inject"))
+		select {
+		case event := <-ctrl.Reloaded:
+			assert.Equal(t, []string{"rady.watched.name"}, event.Keys)
+			assert.Equal(t, []string{"GetWatchedClient"}, event.Factories)
+		case <-time.After(2 * time.Second):
+			t.Fatal("config file is not reloaded while a request is handled")
+		}
+		return nil
+	})(request))
+	assert.Equal(t, "inject", *ctrl.Name)
+	assert.Equal(t, "inject", ctrl.Client.Name)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-stopped)
+}
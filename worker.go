@@ -0,0 +1,127 @@
+package rady
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+/*
+Runnable can be implemented by any singleton bean, to run in background while app is serving
+
+Run is called in a new goroutine when server starts, ctx is canceled when app shutdown,
+app shutdown when Run returns an error other than ctx.Err()
+
+Usage:
+
+	type Consumer struct {
+		Component
+	}
+
+	func (c *Consumer) Run(ctx context.Context) error {
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case message := <-c.Messages:
+				c.handle(message)
+			}
+		}
+	}
+*/
+type Runnable interface {
+	Run(ctx context.Context) error
+}
+
+// RunnableType is the type of Runnable
+var RunnableType = reflect.TypeOf((*Runnable)(nil)).Elem()
+
+/*
+Workers is Runnable beans running in background, collected when app is built and started when server starts
+
+Failed receives the first error returned by workers unexpectedly
+*/
+type Workers struct {
+	Beans   []*Bean
+	Failed  chan error
+	logger  *Logger
+	cancel  context.CancelFunc
+	wait    sync.WaitGroup
+	once    sync.Once
+	lock    sync.Mutex
+	stopped bool
+}
+
+// NewWorkers is factory function of Workers
+func NewWorkers(beans []*Bean, logger *Logger) *Workers {
+	return &Workers{
+		Beans:  beans,
+		Failed: make(chan error, 1),
+		logger: logger,
+	}
+}
+
+// runnables return singleton beans implement Runnable in dependency order
+func (a *Application) runnables() []*Bean {
+	runnables := make([]*Bean, 0)
+	for _, bean := range a.dependencyOrder() {
+		if !IsScoped(bean) && bean.Type.Implements(RunnableType) {
+			runnables = append(runnables, bean)
+		}
+	}
+	return runnables
+}
+
+// Start call Run of every bean in a new goroutine, it does nothing when workers are stopped
+func (w *Workers) Start() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.stopped {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	for _, bean := range w.Beans {
+		runnable := bean.Value.Addr().Interface().(Runnable)
+		w.logger.Info("Start worker [%s][%s]", bean.Type, bean.Name)
+		w.wait.Add(1)
+		go func(bean *Bean) {
+			defer w.wait.Done()
+			err := runnable.Run(ctx)
+			if err == nil || err == ctx.Err() {
+				w.logger.Info("Worker [%s][%s] returned", bean.Type, bean.Name)
+				return
+			}
+			beanErr := NewBeanError(HookFailed, bean.Type, bean.Name, "", fmt.Sprintf("Run failed: %s", err))
+			beanErr.Err = err
+			w.once.Do(func() {
+				w.Failed <- beanErr
+			})
+			w.logger.Error(beanErr.Error())
+		}(bean)
+	}
+}
+
+// Stop cancel context of workers, and wait them to return until ctx is done, workers not started yet will never start
+func (w *Workers) Stop(ctx context.Context) error {
+	w.lock.Lock()
+	w.stopped = true
+	cancel := w.cancel
+	w.lock.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		w.wait.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package rady
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type (
+	WorkerRoot struct {
+		*WorkerController
+	}
+
+	PollingWorker struct {
+		Component
+		Stopped chan error
+	}
+
+	FailingWorker struct {
+		Component
+	}
+
+	WorkerController struct {
+		Controller
+		Polling *PollingWorker
+		Failing *FailingWorker
+	}
+)
+
+func (p *PollingWorker) PreInit() {
+	p.Stopped = make(chan error, 1)
+}
+
+func (p *PollingWorker) Run(ctx context.Context) error {
+	<-ctx.Done()
+	p.Stopped <- ctx.Err()
+	return ctx.Err()
+}
+
+func (f *FailingWorker) Run(ctx context.Context) error {
+	time.Sleep(10 * time.Millisecond)
+	return errors.New("queue closed")
+}
+
+func TestWorkers(t *testing.T) {
+	app, err := CreateApplication(new(WorkerRoot)).Build()
+	assert.Nil(t, err)
+	assert.Len(t, app.workers.Beans, 3)
+	*app.Addr = "127.0.0.1:0"
+
+	err = app.serve()
+	hookFailed := err.(*BeanError)
+	assert.Equal(t, HookFailed, hookFailed.Kind)
+	assert.Equal(t, reflect.TypeOf(new(FailingWorker)), hookFailed.Type)
+	assert.Equal(t, "queue closed", hookFailed.Err.Error())
+
+	polling := app.BeanMap[reflect.TypeOf(new(PollingWorker))]["*rady.PollingWorker"].Value.Addr().Interface().(*PollingWorker)
+	assert.Equal(t, context.Canceled, <-polling.Stopped)
+}
+
+type (
+	RunnableRoot struct {
+		*RunnableController
+	}
+
+	RunnableController struct {
+		Controller
+		Polling *PollingWorker
+		Worker  Runnable   `type:"component"`
+		Workers []Runnable `type:"component"`
+	}
+)
+
+func TestRunnableInjection(t *testing.T) {
+	app, err := CreateApplication(new(RunnableRoot)).Build()
+	assert.Nil(t, err)
+	ctrl := app.BeanMap[reflect.TypeOf(new(RunnableController))]["*rady.RunnableController"].Value.Addr().Interface().(*RunnableController)
+	assert.True(t, ctrl.Worker == ctrl.Polling)
+	assert.Len(t, ctrl.Workers, 1)
+	assert.Len(t, app.workers.Beans, 2)
+}